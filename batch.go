@@ -0,0 +1,198 @@
+package storage_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrUploadSkipped is the error of a job that was not uploaded because the
+// batch was stopped, either by a failure in fail-fast mode or by its context:
+// the job was not tried, or its upload was cancelled.
+var ErrUploadSkipped = errors.New("storage: upload skipped")
+
+// UploadJob is a file uploaded by a BatchUploader.
+type UploadJob struct {
+	// Path is the file path, including the file name. Should be of the format `folder/subfolder/filename.png`
+	Path string
+	// Data is the file data. If it implements io.Closer, it is closed once the
+	// job is done or skipped.
+	Data    io.Reader
+	Options FileOptions
+}
+
+// UploadResult is the outcome of an UploadJob.
+type UploadResult struct {
+	Path string
+	// Key is the key of the uploaded file, e.g. `bucket-id/folder/filename.png`.
+	Key string
+	// Err is nil if the file was uploaded, and ErrUploadSkipped if it was not
+	// tried or was cancelled because the batch was stopped.
+	Err error
+}
+
+// BatchReport holds the results of a batch, in the order the jobs were received.
+type BatchReport struct {
+	Results   []UploadResult
+	Succeeded int
+	Failed    int
+	Skipped   int
+}
+
+// BatchUploadOptions configures a BatchUploader.
+type BatchUploadOptions struct {
+	// Concurrency is the number of uploads run in parallel. Defaults to 4.
+	Concurrency int
+	// FailFast stops the batch at the first failed upload: uploads in progress
+	// are cancelled and, like the remaining jobs, skipped. By default every job
+	// is tried.
+	FailFast bool
+	// RetryPolicy replaces the retry policy of the client for the uploads. Only
+	// jobs whose Data is in memory or an io.Seeker can be retried.
+	RetryPolicy *RetryPolicy
+}
+
+// BatchUploader uploads many files to a bucket concurrently. It is safe for
+// concurrent use.
+type BatchUploader struct {
+	client   *Client
+	bucketId string
+	options  BatchUploadOptions
+}
+
+// NewBatchUploader creates an uploader for a bucket.
+// bucketId string The bucket id
+// options BatchUploadOptions The concurrency, failure mode and retry policy
+func (c *Client) NewBatchUploader(bucketId string, options ...BatchUploadOptions) *BatchUploader {
+	b := &BatchUploader{client: c, bucketId: bucketId}
+	if len(options) > 0 {
+		b.options = options[0]
+	}
+	if b.options.Concurrency <= 0 {
+		b.options.Concurrency = 4
+	}
+	return b
+}
+
+// Upload uploads the jobs received from jobs until it is closed, and returns
+// a report of every job. The error is nil if all jobs were uploaded. Otherwise
+// it is the first failure in fail-fast mode, an error wrapping the first
+// failure in best-effort mode, or the error of the context.
+//
+// Upload keeps receiving from jobs after the batch was stopped, so the
+// producer is never blocked, and returns once jobs is closed.
+func (b *BatchUploader) Upload(jobs <-chan UploadJob) (BatchReport, error) {
+	return b.UploadWithContext(context.Background(), jobs)
+}
+
+// UploadWithContext is like Upload but carries ctx on the outgoing requests.
+// Cancelling ctx cancels the uploads in progress; they are reported as
+// skipped, like the remaining jobs.
+func (b *BatchUploader) UploadWithContext(ctx context.Context, jobs <-chan UploadJob) (BatchReport, error) {
+	batchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if b.options.RetryPolicy != nil {
+		batchCtx = ContextWithRetryPolicy(batchCtx, *b.options.RetryPolicy)
+	}
+
+	type indexedJob struct {
+		index int
+		job   UploadJob
+	}
+
+	var (
+		mu       sync.Mutex
+		results  []UploadResult
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	work := make(chan indexedJob)
+	for i := 0; i < b.options.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for w := range work {
+				result := b.upload(batchCtx, w.job)
+				mu.Lock()
+				if result.Err != nil && batchCtx.Err() != nil && errors.Is(result.Err, batchCtx.Err()) {
+					// The upload was cancelled because the batch was stopped,
+					// by a failure in fail-fast mode or by ctx.
+					result.Err = ErrUploadSkipped
+				}
+				results[w.index] = result
+				if result.Err != nil && firstErr == nil {
+					firstErr = result.Err
+					if b.options.FailFast {
+						cancel()
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for job := range jobs {
+		mu.Lock()
+		index := len(results)
+		results = append(results, UploadResult{Path: job.Path})
+		mu.Unlock()
+
+		select {
+		case <-batchCtx.Done():
+		default:
+			select {
+			case work <- indexedJob{index: index, job: job}:
+				continue
+			case <-batchCtx.Done():
+			}
+		}
+
+		mu.Lock()
+		results[index].Err = ErrUploadSkipped
+		mu.Unlock()
+		closeData(job.Data)
+	}
+	close(work)
+	wg.Wait()
+
+	report := BatchReport{Results: results}
+	for _, result := range results {
+		switch {
+		case result.Err == nil:
+			report.Succeeded++
+		case result.Err == ErrUploadSkipped:
+			report.Skipped++
+		default:
+			report.Failed++
+		}
+	}
+
+	switch {
+	case ctx.Err() != nil:
+		return report, ctx.Err()
+	case firstErr == nil:
+		return report, nil
+	case b.options.FailFast:
+		return report, firstErr
+	}
+	return report, fmt.Errorf("storage: %d of %d uploads failed: %w", report.Failed, len(results), firstErr)
+}
+
+// upload uploads a single job and closes its data.
+func (b *BatchUploader) upload(ctx context.Context, job UploadJob) UploadResult {
+	defer closeData(job.Data)
+
+	resp, err := b.client.UploadFileWithContext(ctx, b.bucketId, job.Path, job.Data, job.Options)
+	if err != nil {
+		return UploadResult{Path: job.Path, Err: err}
+	}
+	return UploadResult{Path: job.Path, Key: resp.Key}
+}
+
+func closeData(data io.Reader) {
+	if closer, ok := data.(io.Closer); ok {
+		closer.Close()
+	}
+}
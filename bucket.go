@@ -1,14 +1,20 @@
 package storage_go
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 )
 
 // ListBuckets retrieves the details of all Storage buckets within an existing project.
 func (c *Client) ListBuckets() ([]Bucket, error) {
+	return c.ListBucketsWithContext(context.Background())
+}
+
+// ListBucketsWithContext is like ListBuckets but carries ctx on the outgoing request.
+func (c *Client) ListBucketsWithContext(ctx context.Context) ([]Bucket, error) {
 	bucketsURL := c.clientTransport.baseUrl.String() + "/bucket"
-	req, err := c.NewRequest(http.MethodGet, bucketsURL, nil)
+	req, err := c.NewRequestWithContext(ctx, http.MethodGet, bucketsURL, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -24,8 +30,13 @@ func (c *Client) ListBuckets() ([]Bucket, error) {
 
 // GetBucket retrieves the details of an existing Storage bucket.
 func (c *Client) GetBucket(id string) (Bucket, error) {
+	return c.GetBucketWithContext(context.Background(), id)
+}
+
+// GetBucketWithContext is like GetBucket but carries ctx on the outgoing request.
+func (c *Client) GetBucketWithContext(ctx context.Context, id string) (Bucket, error) {
 	bucketURL := c.clientTransport.baseUrl.String() + "/bucket/" + id
-	req, err := c.NewRequest(http.MethodGet, bucketURL, nil)
+	req, err := c.NewRequestWithContext(ctx, http.MethodGet, bucketURL, nil)
 	if err != nil {
 		return Bucket{}, err
 	}
@@ -45,6 +56,11 @@ func (c *Client) GetBucket(id string) (Bucket, error) {
 // options.allowedMimeTypes The list of allowed MIME types. By default, all MIME types are allowed.
 // return newly created bucket id
 func (c *Client) CreateBucket(id string, options BucketOptions) (Bucket, error) {
+	return c.CreateBucketWithContext(context.Background(), id, options)
+}
+
+// CreateBucketWithContext is like CreateBucket but carries ctx on the outgoing request.
+func (c *Client) CreateBucketWithContext(ctx context.Context, id string, options BucketOptions) (Bucket, error) {
 	createBucketURL := c.clientTransport.baseUrl.String() + "/bucket"
 	bodyData := map[string]interface{}{
 		"id":     id,
@@ -60,7 +76,7 @@ func (c *Client) CreateBucket(id string, options BucketOptions) (Bucket, error)
 		bodyData["allowed_mime_types"] = options.AllowedMimeTypes
 	}
 	// jsonBody, _ := json.Marshal(bodyData)
-	req, err := c.NewRequest(http.MethodPost, createBucketURL, &bodyData)
+	req, err := c.NewRequestWithContext(ctx, http.MethodPost, createBucketURL, &bodyData)
 	if err != nil {
 		return Bucket{}, err
 	}
@@ -80,6 +96,11 @@ func (c *Client) CreateBucket(id string, options BucketOptions) (Bucket, error)
 // options.allowedMimeTypes The list of allowed MIME types. By default, all MIME types are allowed.
 // return newly updated bucket id
 func (c *Client) UpdateBucket(id string, options BucketOptions) (MessageResponse, error) {
+	return c.UpdateBucketWithContext(context.Background(), id, options)
+}
+
+// UpdateBucketWithContext is like UpdateBucket but carries ctx on the outgoing request.
+func (c *Client) UpdateBucketWithContext(ctx context.Context, id string, options BucketOptions) (MessageResponse, error) {
 	bucketURL := c.clientTransport.baseUrl.String() + "/bucket/" + id
 	bodyData := map[string]interface{}{
 		"id":     id,
@@ -94,7 +115,7 @@ func (c *Client) UpdateBucket(id string, options BucketOptions) (MessageResponse
 	if len(options.AllowedMimeTypes) > 0 {
 		bodyData["allowed_mime_types"] = options.AllowedMimeTypes
 	}
-	req, err := c.NewRequest(http.MethodPut, bucketURL, &bodyData)
+	req, err := c.NewRequestWithContext(ctx, http.MethodPut, bucketURL, &bodyData)
 	if err != nil {
 		return MessageResponse{}, err
 	}
@@ -109,9 +130,14 @@ func (c *Client) UpdateBucket(id string, options BucketOptions) (MessageResponse
 
 // EmptyBucket removes all objects inside a single bucket.
 func (c *Client) EmptyBucket(id string) (MessageResponse, error) {
+	return c.EmptyBucketWithContext(context.Background(), id)
+}
+
+// EmptyBucketWithContext is like EmptyBucket but carries ctx on the outgoing request.
+func (c *Client) EmptyBucketWithContext(ctx context.Context, id string) (MessageResponse, error) {
 	bucketURL := c.clientTransport.baseUrl.String() + "/bucket/" + id + "/empty"
 	jsonBody, _ := json.Marshal(map[string]interface{}{})
-	req, err := c.NewRequest(http.MethodPost, bucketURL, &jsonBody)
+	req, err := c.NewRequestWithContext(ctx, http.MethodPost, bucketURL, &jsonBody)
 	if err != nil {
 		return MessageResponse{}, err
 	}
@@ -124,9 +150,14 @@ func (c *Client) EmptyBucket(id string) (MessageResponse, error) {
 
 // DeleteBucket deletes an existing bucket. A bucket must be empty before it can be deleted.
 func (c *Client) DeleteBucket(id string) (MessageResponse, error) {
+	return c.DeleteBucketWithContext(context.Background(), id)
+}
+
+// DeleteBucketWithContext is like DeleteBucket but carries ctx on the outgoing request.
+func (c *Client) DeleteBucketWithContext(ctx context.Context, id string) (MessageResponse, error) {
 	bucketURL := c.clientTransport.baseUrl.String() + "/bucket/" + id
 	jsonBody, _ := json.Marshal(map[string]interface{}{})
-	req, err := c.NewRequest(http.MethodDelete, bucketURL, &jsonBody)
+	req, err := c.NewRequestWithContext(ctx, http.MethodDelete, bucketURL, &jsonBody)
 	if err != nil {
 		return MessageResponse{}, err
 	}
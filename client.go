@@ -2,6 +2,7 @@ package storage_go
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
@@ -10,62 +11,129 @@ import (
 
 var version = "v0.7.0"
 
+// Client is a Supabase Storage API client. A Client is safe for concurrent use
+// by multiple goroutines: the headers configured in NewClient are never
+// modified afterwards, and per-call options are carried on each request.
 type Client struct {
 	clientError     error
 	session         http.Client
 	clientTransport transport
+	retryPolicy     RetryPolicy
 }
 
+// transport adds the client-wide headers to every request and resolves its
+// URL against baseUrl before handing it to next. header must not be modified
+// after the Client is created.
 type transport struct {
 	header  http.Header
 	baseUrl url.URL
+	next    http.RoundTripper
 }
 
 func (t transport) RoundTrip(request *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the caller's request.
+	request = request.Clone(request.Context())
 	for headerName, values := range t.header {
-		for _, val := range values {
-			request.Header.Add(headerName, val)
+		// Headers set on the request itself take precedence.
+		if _, ok := request.Header[headerName]; ok {
+			continue
 		}
+		request.Header[headerName] = append([]string(nil), values...)
 	}
 	request.URL = t.baseUrl.ResolveReference(request.URL)
-	return http.DefaultTransport.RoundTrip(request)
+	return t.next.RoundTrip(request)
 }
 
+// NewClient creates a client for the Storage API at rawUrl, authenticated
+// with token and sending headers with every request. If rawUrl is invalid,
+// every call of the client returns the parse error; use NewClientWithOptions
+// to get it up front.
 func NewClient(rawUrl string, token string, headers map[string]string) *Client {
-	baseURL, err := url.Parse(rawUrl)
+	c, err := NewClientWithOptions(rawUrl, WithToken(token), WithHeaders(headers))
 	if err != nil {
 		return &Client{
 			clientError: err,
 		}
 	}
+	return c
+}
+
+// NewClientWithOptions creates a client for the Storage API at rawUrl, e.g.
+// `https://<project-reference-id>.supabase.co/storage/v1`, configured by options.
+func NewClientWithOptions(rawUrl string, options ...Option) (*Client, error) {
+	baseURL, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, invalidURLError(err)
+	}
+
+	var o clientOptions
+	for _, option := range options {
+		option(&o)
+	}
+
+	var session http.Client
+	if o.httpClient != nil {
+		session = *o.httpClient
+	}
+	next := o.transport
+	if next == nil {
+		next = session.Transport
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	for i := len(o.middleware) - 1; i >= 0; i-- {
+		next = o.middleware[i](next)
+	}
+	if o.timeout > 0 {
+		session.Timeout = o.timeout
+	}
 
 	t := transport{
 		header:  http.Header{},
 		baseUrl: *baseURL,
+		next:    next,
 	}
+	session.Transport = t
 
 	c := Client{
-		session:         http.Client{Transport: t},
+		session:         session,
 		clientTransport: t,
+		retryPolicy:     DefaultRetryPolicy,
+	}
+	if o.retryPolicy != nil {
+		c.retryPolicy = *o.retryPolicy
 	}
 
 	// Set required headers
 	c.clientTransport.header.Set("Accept", "application/json")
 	c.clientTransport.header.Set("Content-Type", "application/json")
 	c.clientTransport.header.Set("X-Client-Info", "storage-go/"+version)
-	c.clientTransport.header.Set("Authorization", "Bearer "+token)
+	c.clientTransport.header.Set("Authorization", "Bearer "+o.token)
+	if o.userAgent != "" {
+		c.clientTransport.header.Set("User-Agent", o.userAgent)
+	}
 
 	// Optional headers [if exists]
-	for key, value := range headers {
+	for key, value := range o.headers {
 		c.clientTransport.header.Set(key, value)
 	}
 
-	return &c
+	return &c, nil
 }
 
 // NewRequest will create new request with method, url and body
 // If body is not nil, it will be marshalled into json
 func (c *Client) NewRequest(method, url string, body ...interface{}) (*http.Request, error) {
+	return c.NewRequestWithContext(context.Background(), method, url, body...)
+}
+
+// NewRequestWithContext is like NewRequest but attaches ctx to the request,
+// so cancelling ctx aborts the request and any read of its response body.
+func (c *Client) NewRequestWithContext(ctx context.Context, method, url string, body ...interface{}) (*http.Request, error) {
+	if c.clientError != nil {
+		return nil, c.clientError
+	}
 	var buf io.ReadWriter
 	if len(body) > 0 && body[0] != nil {
 		buf = &bytes.Buffer{}
@@ -76,7 +144,7 @@ func (c *Client) NewRequest(method, url string, body ...interface{}) (*http.Requ
 			return nil, err
 		}
 	}
-	req, err := http.NewRequest(method, url, buf)
+	req, err := http.NewRequestWithContext(ctx, method, url, buf)
 	if err != nil {
 		return nil, err
 	}
@@ -86,10 +154,41 @@ func (c *Client) NewRequest(method, url string, body ...interface{}) (*http.Requ
 // Do will send request using the c.sessionon which it is called
 // If response contains body, it will be unmarshalled into v
 // If response has err, it will be returned
+// Failed attempts are retried according to the retry policy, see RetryPolicy.
 func (c *Client) Do(req *http.Request, v interface{}) (*http.Response, error) {
-	resp, err := c.session.Do(req)
+	if c.clientError != nil {
+		return nil, c.clientError
+	}
+
+	policy := c.retryPolicyFor(req)
+	if !isRetryable(req) {
+		policy = NoRetry
+	}
+
+	var resp *http.Response
+	var err error
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			req.Body, err = req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err = c.session.Do(req)
+		delay, retry := policy.shouldRetry(req, resp, err, attempt)
+		if !retry {
+			break
+		}
+		if resp != nil {
+			discardBody(resp)
+		}
+		if err := sleepContext(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
 	if err != nil {
-		return nil, err
+		return nil, contextError(req.Context(), err)
 	}
 
 	err = checkForError(resp)
@@ -101,7 +200,7 @@ func (c *Client) Do(req *http.Request, v interface{}) (*http.Response, error) {
 		defer resp.Body.Close()
 		body, err := io.ReadAll(resp.Body)
 		if err != nil {
-			return resp, err
+			return resp, contextError(req.Context(), err)
 		}
 		err = json.Unmarshal(body, &v)
 		if err != nil {
@@ -112,17 +211,23 @@ func (c *Client) Do(req *http.Request, v interface{}) (*http.Response, error) {
 	return resp, nil
 }
 
+// contextError prefers the context error of ctx over err, so callers can
+// match context.Canceled or context.DeadlineExceeded when a body read is
+// interrupted.
+func contextError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
 func checkForError(resp *http.Response) error {
 	if c := resp.StatusCode; 200 <= c && c < 400 {
 		return nil
 	}
 
-	errorResponse := &StorageError{}
-
-	data, err := io.ReadAll(resp.Body)
-	if err == nil && data != nil {
-		_ = json.Unmarshal(data, errorResponse)
-	}
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
 
-	return errorResponse
+	return newStorageErrorFromResponse(resp, data)
 }
@@ -1,12 +1,73 @@
 package storage_go
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Sentinel errors matched by StorageError with errors.Is.
+var (
+	ErrNotFound        = errors.New("storage: not found")
+	ErrConflict        = errors.New("storage: conflict")
+	ErrUnauthorized    = errors.New("storage: unauthorized")
+	ErrPayloadTooLarge = errors.New("storage: payload too large")
+)
+
+// StorageError is returned when the Storage API answers with an error status.
+// Use errors.Is with ErrNotFound, ErrConflict, ErrUnauthorized or
+// ErrPayloadTooLarge to check for common failures, and errors.As to inspect it.
 type StorageError struct {
-	Status  int    `json:"status"`
+	// Status is the HTTP status code of the response.
+	Status int `json:"status"`
+	// Code is the error code of the server, e.g. `not_found` or `Duplicate`.
+	Code    string `json:"error"`
 	Message string `json:"message"`
+	// Method and URL identify the request that failed.
+	Method string `json:"-"`
+	URL    string `json:"-"`
+
+	// bodyStatus is the statusCode reported in the error body. Some servers
+	// answer with a 400 whose body holds the actual status, e.g. "404".
+	bodyStatus int
 }
 
 func (e *StorageError) Error() string {
-	return e.Message
+	var b strings.Builder
+	b.WriteString("storage: ")
+	if e.Method != "" {
+		b.WriteString(e.Method + " " + e.URL + ": ")
+	}
+	if e.Status != 0 {
+		b.WriteString(strconv.Itoa(e.Status) + " ")
+	}
+	if e.Code != "" && e.Code != e.Message {
+		b.WriteString(e.Code + ": ")
+	}
+	b.WriteString(e.Message)
+	return b.String()
+}
+
+// Is reports whether e matches one of the sentinel errors.
+func (e *StorageError) Is(target error) bool {
+	status := e.Status
+	if e.bodyStatus != 0 {
+		status = e.bodyStatus
+	}
+	switch target {
+	case ErrNotFound:
+		return status == http.StatusNotFound
+	case ErrConflict:
+		return status == http.StatusConflict
+	case ErrUnauthorized:
+		return status == http.StatusUnauthorized || status == http.StatusForbidden
+	case ErrPayloadTooLarge:
+		return status == http.StatusRequestEntityTooLarge
+	}
+	return false
 }
 
 func NewStorageError(err error, statusCode int) StorageError {
@@ -15,3 +76,48 @@ func NewStorageError(err error, statusCode int) StorageError {
 		Message: err.Error(),
 	}
 }
+
+// errorResponse is the error body of the Storage API. statusCode is a string
+// on most servers but a number on some.
+type errorResponse struct {
+	StatusCode json.RawMessage `json:"statusCode"`
+	Code       string          `json:"code"`
+	Error      string          `json:"error"`
+	Message    string          `json:"message"`
+}
+
+// newStorageErrorFromResponse builds the error of a failed response from its body.
+func newStorageErrorFromResponse(resp *http.Response, body []byte) *StorageError {
+	storageErr := &StorageError{Status: resp.StatusCode}
+	if resp.Request != nil {
+		storageErr.Method = resp.Request.Method
+		storageErr.URL = resp.Request.URL.String()
+	}
+
+	var parsed errorResponse
+	if json.Unmarshal(body, &parsed) == nil {
+		storageErr.Code = parsed.Code
+		if storageErr.Code == "" {
+			storageErr.Code = parsed.Error
+		}
+		storageErr.Message = parsed.Message
+		var status string
+		if json.Unmarshal(parsed.StatusCode, &status) != nil {
+			status = string(parsed.StatusCode)
+		}
+		storageErr.bodyStatus, _ = strconv.Atoi(status)
+	}
+	if storageErr.Message == "" {
+		storageErr.Message = strings.TrimSpace(string(body))
+	}
+	if storageErr.Message == "" {
+		storageErr.Message = http.StatusText(resp.StatusCode)
+	}
+
+	return storageErr
+}
+
+// invalidURLError wraps the error of a base URL that could not be parsed.
+func invalidURLError(err error) error {
+	return fmt.Errorf("storage: invalid base URL: %w", err)
+}
@@ -0,0 +1,253 @@
+package storage_go
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FS is a read-only view of a bucket, or of a folder in a bucket, as an
+// fs.FS. It can be passed to template.ParseFS, http.FS or fs.WalkDir.
+//
+// Folders are listed with ListFiles and files are downloaded with DownloadFile
+// when they are opened, so the content of a file is held in memory while it
+// is open. Use OpenRemoteObject to read large files.
+type FS struct {
+	client   *Client
+	ctx      context.Context
+	bucketId string
+	prefix   string
+}
+
+var (
+	_ fs.ReadDirFS  = (*FS)(nil)
+	_ fs.StatFS     = (*FS)(nil)
+	_ fs.ReadFileFS = (*FS)(nil)
+)
+
+// NewFS returns a file system for the files of a bucket below prefix.
+// client *Client The client used to list and download files
+// bucketId string The bucket id
+// prefix string The folder used as the root of the file system, or an empty string for the whole bucket
+func NewFS(client *Client, bucketId string, prefix string) *FS {
+	return NewFSWithContext(context.Background(), client, bucketId, prefix)
+}
+
+// NewFSWithContext is like NewFS but carries ctx on the outgoing requests of
+// the file system.
+func NewFSWithContext(ctx context.Context, client *Client, bucketId string, prefix string) *FS {
+	return &FS{
+		client:   client,
+		ctx:      ctx,
+		bucketId: bucketId,
+		prefix:   strings.Trim(prefix, "/"),
+	}
+}
+
+// Open opens the named file or folder.
+func (f *FS) Open(name string) (fs.File, error) {
+	info, err := f.stat("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return &fsDir{fs: f, name: name, info: info}, nil
+	}
+
+	data, err := f.readFile("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return &fsFile{Reader: bytes.NewReader(data), info: info}, nil
+}
+
+// Stat returns the fs.FileInfo of the named file or folder. Files report the
+// size and modification time of their metadata.
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	return f.stat("stat", name)
+}
+
+// ReadDir reads the named folder and returns its entries sorted by name.
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	info, err := f.stat("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	entries, err := f.readDir(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	return entries, nil
+}
+
+// ReadFile downloads the named file.
+func (f *FS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrInvalid}
+	}
+	return f.readFile("readfile", name)
+}
+
+// key returns the object key of a valid fs path.
+func (f *FS) key(name string) string {
+	if name == "." {
+		return f.prefix
+	}
+	return joinKey(f.prefix, name)
+}
+
+// stat finds name in the listing of its parent folder.
+func (f *FS) stat(op string, name string) (*fsFileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return &fsFileInfo{name: ".", dir: true}, nil
+	}
+
+	entries, err := f.readDir(path.Dir(name))
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	base := path.Base(name)
+	i := sort.Search(len(entries), func(i int) bool { return entries[i].Name() >= base })
+	if i == len(entries) || entries[i].Name() != base {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return entries[i].(*fsFileInfo), nil
+}
+
+// readDir lists all entries of the folder name, sorted by name.
+func (f *FS) readDir(name string) ([]fs.DirEntry, error) {
+	var entries []fs.DirEntry
+	it := f.client.ListFilesIterWithContext(f.ctx, f.bucketId, f.key(name))
+	for it.Next() {
+		entries = append(entries, newFSFileInfo(it.Object()))
+	}
+	if err := it.Err(); err != nil {
+		return nil, fsError(err)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+func (f *FS) readFile(op string, name string) ([]byte, error) {
+	data, err := f.client.DownloadFileWithContext(f.ctx, f.bucketId, f.key(name))
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fsError(err)}
+	}
+	return data, nil
+}
+
+// fsError maps a missing object or bucket to fs.ErrNotExist and a rejected
+// token to fs.ErrPermission.
+func fsError(err error) error {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return fs.ErrNotExist
+	case errors.Is(err, ErrUnauthorized):
+		return fs.ErrPermission
+	}
+	return err
+}
+
+// fsFileInfo describes a file or folder of a listing. It implements both
+// fs.FileInfo and fs.DirEntry.
+type fsFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func newFSFileInfo(file FileObject) *fsFileInfo {
+	info := &fsFileInfo{
+		name:    file.Name,
+		size:    file.Metadata.Size,
+		modTime: file.UpdatedAt,
+		dir:     file.IsFolder(),
+	}
+	if info.modTime.IsZero() {
+		info.modTime = file.Metadata.LastModified
+	}
+	return info
+}
+
+func (i *fsFileInfo) Name() string       { return i.name }
+func (i *fsFileInfo) Size() int64        { return i.size }
+func (i *fsFileInfo) ModTime() time.Time { return i.modTime }
+func (i *fsFileInfo) IsDir() bool        { return i.dir }
+func (i *fsFileInfo) Sys() interface{}   { return nil }
+
+func (i *fsFileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+func (i *fsFileInfo) Type() fs.FileMode          { return i.Mode().Type() }
+func (i *fsFileInfo) Info() (fs.FileInfo, error) { return i, nil }
+
+// fsFile is an open file. Its content was downloaded when it was opened.
+type fsFile struct {
+	*bytes.Reader
+	info *fsFileInfo
+}
+
+func (f *fsFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *fsFile) Close() error               { return nil }
+
+// fsDir is an open folder. Its entries are listed on the first call to ReadDir.
+type fsDir struct {
+	fs      *FS
+	name    string
+	info    *fsFileInfo
+	entries []fs.DirEntry
+	listed  bool
+	offset  int
+}
+
+func (d *fsDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *fsDir) Close() error               { return nil }
+
+func (d *fsDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+
+// ReadDir returns the next n entries of the folder, or all remaining entries
+// if n <= 0.
+func (d *fsDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.listed {
+		entries, err := d.fs.readDir(d.name)
+		if err != nil {
+			return nil, &fs.PathError{Op: "readdir", Path: d.name, Err: err}
+		}
+		d.entries = entries
+		d.listed = true
+	}
+
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(remaining) {
+		n = len(remaining)
+	}
+	d.offset += n
+	return remaining[:n], nil
+}
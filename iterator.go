@@ -0,0 +1,155 @@
+package storage_go
+
+import "context"
+
+// IterOptions configures ListFilesIter.
+type IterOptions struct {
+	// FileSearchOptions sets the page size, the starting offset and the sort
+	// order. Limit is the size of each page and defaults to 100.
+	FileSearchOptions
+	// Prefetch fetches the next page in the background while the current one
+	// is being iterated.
+	Prefetch bool
+}
+
+// FileIterator pages through the files of a folder. Call Next to advance it
+// and Object to read the current file:
+//
+//	it := client.ListFilesIter("bucket-id", "folder/")
+//	defer it.Close()
+//	for it.Next() {
+//		file := it.Object()
+//	}
+//	if err := it.Err(); err != nil {
+//		// handle the error
+//	}
+//
+// A FileIterator is not safe for concurrent use.
+type FileIterator struct {
+	client    *Client
+	ctx       context.Context
+	cancel    context.CancelFunc
+	bucketId  string
+	queryPath string
+	options   FileSearchOptions
+	prefetch  bool
+
+	page    []FileObject
+	index   int
+	current FileObject
+	next    chan filePage
+	done    bool
+	err     error
+}
+
+type filePage struct {
+	files []FileObject
+	err   error
+}
+
+// ListFilesIter returns an iterator over all files in a folder, fetching them
+// one page at a time with ListFiles.
+// bucketId string The bucket id
+// queryPath string The folder path
+// options IterOptions The page size, sort order and prefetching options
+func (c *Client) ListFilesIter(bucketId string, queryPath string, options ...IterOptions) *FileIterator {
+	return c.ListFilesIterWithContext(context.Background(), bucketId, queryPath, options...)
+}
+
+// ListFilesIterWithContext is like ListFilesIter but carries ctx on the
+// outgoing requests.
+func (c *Client) ListFilesIterWithContext(ctx context.Context, bucketId string, queryPath string, options ...IterOptions) *FileIterator {
+	ctx, cancel := context.WithCancel(ctx)
+	it := &FileIterator{
+		client:    c,
+		ctx:       ctx,
+		cancel:    cancel,
+		bucketId:  bucketId,
+		queryPath: queryPath,
+	}
+	if len(options) > 0 {
+		it.options = options[0].FileSearchOptions
+		it.prefetch = options[0].Prefetch
+	}
+	if it.options.Limit <= 0 {
+		it.options.Limit = defaultLimit
+	}
+
+	return it
+}
+
+// Next advances the iterator to the next file. It returns false when there
+// are no more files or an error occurred, which Err reports.
+func (it *FileIterator) Next() bool {
+	for it.index >= len(it.page) {
+		if it.done {
+			return false
+		}
+
+		files, err := it.fetch()
+		if err != nil {
+			it.err = err
+			it.Close()
+			return false
+		}
+		it.page = files
+		it.index = 0
+		it.options.Offset += len(files)
+
+		if len(files) < it.options.Limit {
+			// There is nothing left to fetch, so release the context without
+			// waiting for Close.
+			it.done = true
+			it.cancel()
+		} else if it.prefetch {
+			it.startPrefetch()
+		}
+	}
+
+	it.current = it.page[it.index]
+	it.index++
+	return true
+}
+
+// Object returns the current file.
+func (it *FileIterator) Object() FileObject {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *FileIterator) Err() error {
+	return it.err
+}
+
+// Close stops the iteration and cancels a page that is being prefetched. It
+// is only needed when the iteration is stopped before Next returns false, and
+// can be called more than once.
+func (it *FileIterator) Close() {
+	it.done = true
+	it.page = nil
+	it.index = 0
+	it.cancel()
+}
+
+// fetch returns the page at the current offset, waiting for it if it is
+// being prefetched.
+func (it *FileIterator) fetch() ([]FileObject, error) {
+	if it.next != nil {
+		page := <-it.next
+		it.next = nil
+		return page.files, page.err
+	}
+	return it.client.ListFilesWithContext(it.ctx, it.bucketId, it.queryPath, it.options)
+}
+
+// startPrefetch starts fetching the page at the current offset. The channel
+// is buffered so the goroutine never blocks when the iteration is stopped.
+func (it *FileIterator) startPrefetch() {
+	options := it.options
+	next := make(chan filePage, 1)
+	go func() {
+		files, err := it.client.ListFilesWithContext(it.ctx, it.bucketId, it.queryPath, options)
+		next <- filePage{files: files, err: err}
+	}()
+	it.next = next
+}
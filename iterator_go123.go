@@ -0,0 +1,43 @@
+//go:build go1.23
+
+package storage_go
+
+import (
+	"context"
+	"iter"
+)
+
+// ListFilesAll returns an iterator over all files in a folder for use with a
+// range loop. An error stops the iteration and is yielded with an empty
+// FileObject:
+//
+//	for file, err := range client.ListFilesAll("bucket-id", "folder/") {
+//		if err != nil {
+//			return err
+//		}
+//	}
+//
+// bucketId string The bucket id
+// queryPath string The folder path
+// options IterOptions The page size, sort order and prefetching options
+func (c *Client) ListFilesAll(bucketId string, queryPath string, options ...IterOptions) iter.Seq2[FileObject, error] {
+	return c.ListFilesAllWithContext(context.Background(), bucketId, queryPath, options...)
+}
+
+// ListFilesAllWithContext is like ListFilesAll but carries ctx on the
+// outgoing requests.
+func (c *Client) ListFilesAllWithContext(ctx context.Context, bucketId string, queryPath string, options ...IterOptions) iter.Seq2[FileObject, error] {
+	return func(yield func(FileObject, error) bool) {
+		it := c.ListFilesIterWithContext(ctx, bucketId, queryPath, options...)
+		defer it.Close()
+
+		for it.Next() {
+			if !yield(it.Object(), nil) {
+				return
+			}
+		}
+		if err := it.Err(); err != nil {
+			yield(FileObject{}, err)
+		}
+	}
+}
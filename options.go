@@ -0,0 +1,91 @@
+package storage_go
+
+import (
+	"net/http"
+	"time"
+)
+
+// Option configures a Client created with NewClientWithOptions.
+type Option func(*clientOptions)
+
+// Middleware wraps the RoundTripper that sends the requests of a Client. The
+// request it receives already has the client headers and an absolute URL.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+type clientOptions struct {
+	token       string
+	headers     map[string]string
+	userAgent   string
+	httpClient  *http.Client
+	transport   http.RoundTripper
+	timeout     time.Duration
+	middleware  []Middleware
+	retryPolicy *RetryPolicy
+}
+
+// WithToken sets the token sent as `Authorization: Bearer <token>`.
+func WithToken(token string) Option {
+	return func(o *clientOptions) {
+		o.token = token
+	}
+}
+
+// WithHeaders adds headers sent with every request. Headers set on a request
+// itself take precedence.
+func WithHeaders(headers map[string]string) Option {
+	return func(o *clientOptions) {
+		if o.headers == nil {
+			o.headers = map[string]string{}
+		}
+		for key, value := range headers {
+			o.headers[key] = value
+		}
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request.
+func WithUserAgent(userAgent string) Option {
+	return func(o *clientOptions) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithHTTPClient makes the Client send requests with a copy of httpClient,
+// keeping its timeout, cookie jar and redirect policy. Its Transport is used
+// to send requests unless WithTransport is also given.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *clientOptions) {
+		o.httpClient = httpClient
+	}
+}
+
+// WithTransport sets the RoundTripper that sends requests, in place of
+// http.DefaultTransport. Use it to configure proxies, TLS or connection pools.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *clientOptions) {
+		o.transport = transport
+	}
+}
+
+// WithTimeout limits the time of each attempt of a request, including reading
+// the response body. Use a context to bound a whole call instead.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *clientOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithMiddleware wraps the transport with middleware. The first middleware is
+// the outermost one and sees each request first.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(o *clientOptions) {
+		o.middleware = append(o.middleware, middleware...)
+	}
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy for the Client.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *clientOptions) {
+		o.retryPolicy = &policy
+	}
+}
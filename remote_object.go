@@ -0,0 +1,209 @@
+package storage_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// RemoteObject gives random access to an object in a bucket through HTTP
+// Range requests, so only the bytes that are read are downloaded. It
+// implements io.ReaderAt and io.ReadSeeker and can be passed to
+// archive/zip.NewReader together with Size.
+//
+// ReadAt is safe for concurrent use; Read and Seek share a single offset.
+//
+// ReadAt and Read are not bound to a context; use ReadAtWithContext to cancel
+// a read.
+type RemoteObject struct {
+	client    *Client
+	objectURL string
+	info      ObjectInfo
+	readAhead int
+
+	mu        sync.Mutex
+	offset    int64
+	buf       []byte
+	bufOffset int64
+}
+
+// RemoteObjectOptions configures OpenRemoteObject.
+type RemoteObjectOptions struct {
+	// ReadAhead is the minimum number of bytes fetched per range request.
+	// Smaller reads are answered from the fetched block when possible.
+	// Defaults to 0, which fetches exactly the bytes that are read.
+	ReadAhead int
+}
+
+// OpenRemoteObject opens an object for ranged reads. The size of the object is
+// fetched with a HEAD request.
+// bucketId string The bucket id.
+// filePath string The file path, including the file name. Should be of the format `folder/subfolder/filename.png`
+// options RemoteObjectOptions The read-ahead options
+func (c *Client) OpenRemoteObject(bucketId string, filePath string, options ...RemoteObjectOptions) (*RemoteObject, error) {
+	return c.OpenRemoteObjectWithContext(context.Background(), bucketId, filePath, options...)
+}
+
+// OpenRemoteObjectWithContext is like OpenRemoteObject but carries ctx on the
+// HEAD request. ctx is not used by later reads.
+func (c *Client) OpenRemoteObjectWithContext(ctx context.Context, bucketId string, filePath string, options ...RemoteObjectOptions) (*RemoteObject, error) {
+	info, err := c.headObject(ctx, bucketId, filePath)
+	if err != nil {
+		return nil, err
+	}
+	if info.ContentLength < 0 {
+		return nil, errors.New("storage: remote object: server did not report the object size")
+	}
+
+	object := &RemoteObject{
+		client:    c,
+		objectURL: c.clientTransport.baseUrl.String() + "/object/" + bucketId + "/" + filePath,
+		info:      info,
+	}
+	if len(options) > 0 {
+		object.readAhead = options[0].ReadAhead
+	}
+
+	return object, nil
+}
+
+// Size returns the size of the object in bytes.
+func (o *RemoteObject) Size() int64 {
+	return o.info.ContentLength
+}
+
+// Info returns the metadata reported when the object was opened.
+func (o *RemoteObject) Info() ObjectInfo {
+	return o.info
+}
+
+// ReadAt reads len(p) bytes starting at off.
+func (o *RemoteObject) ReadAt(p []byte, off int64) (int, error) {
+	return o.ReadAtWithContext(context.Background(), p, off)
+}
+
+// ReadAtWithContext is like ReadAt but carries ctx on the outgoing range request.
+func (o *RemoteObject) ReadAtWithContext(ctx context.Context, p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("storage: remote object: negative offset")
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	size := o.Size()
+	if off >= size {
+		return 0, io.EOF
+	}
+	want := p
+	if remaining := size - off; int64(len(want)) > remaining {
+		want = want[:remaining]
+	}
+
+	switch {
+	case o.readBuffered(want, off):
+	case len(want) >= o.readAhead:
+		if err := o.fetchRange(ctx, want, off); err != nil {
+			return 0, err
+		}
+	default:
+		block := make([]byte, minInt64(int64(o.readAhead), size-off))
+		if err := o.fetchRange(ctx, block, off); err != nil {
+			return 0, err
+		}
+		o.mu.Lock()
+		o.buf, o.bufOffset = block, off
+		o.mu.Unlock()
+		copy(want, block)
+	}
+
+	if len(want) < len(p) {
+		return len(want), io.EOF
+	}
+	return len(want), nil
+}
+
+// readBuffered fills p from the read-ahead block if it covers [off, off+len(p)).
+func (o *RemoteObject) readBuffered(p []byte, off int64) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.buf == nil || off < o.bufOffset || off+int64(len(p)) > o.bufOffset+int64(len(o.buf)) {
+		return false
+	}
+	copy(p, o.buf[off-o.bufOffset:])
+	return true
+}
+
+// fetchRange fills p with the bytes of the object starting at off.
+func (o *RemoteObject) fetchRange(ctx context.Context, p []byte, off int64) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.objectURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", off, off+int64(len(p))-1))
+
+	res, err := o.client.Do(req, nil)
+	if res != nil {
+		defer res.Body.Close()
+	}
+	if err != nil {
+		return err
+	}
+
+	switch res.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		// The server ignored the range and sent the whole object.
+		if _, err := io.CopyN(io.Discard, res.Body, off); err != nil {
+			return contextError(ctx, err)
+		}
+	default:
+		return fmt.Errorf("storage: remote object: unexpected status %s for range request", res.Status)
+	}
+
+	if _, err := io.ReadFull(res.Body, p); err != nil {
+		return contextError(ctx, err)
+	}
+	return nil
+}
+
+// Read reads up to len(p) bytes from the current offset.
+func (o *RemoteObject) Read(p []byte) (int, error) {
+	o.mu.Lock()
+	offset := o.offset
+	o.mu.Unlock()
+
+	n, err := o.ReadAt(p, offset)
+
+	o.mu.Lock()
+	o.offset = offset + int64(n)
+	o.mu.Unlock()
+
+	if n > 0 && err == io.EOF {
+		return n, nil
+	}
+	return n, err
+}
+
+// Seek sets the offset for the next Read.
+func (o *RemoteObject) Seek(offset int64, whence int) (int64, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += o.offset
+	case io.SeekEnd:
+		offset += o.Size()
+	default:
+		return 0, errors.New("storage: remote object: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("storage: remote object: negative position")
+	}
+	o.offset = offset
+	return offset, nil
+}
@@ -0,0 +1,250 @@
+package storage_go
+
+import (
+	"context"
+	"errors"
+	"html"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ResponsiveImageOptions configures the URLs of a ResponsiveImage.
+type ResponsiveImageOptions struct {
+	// Widths are the widths of the image, in pixels, described with `w`
+	// descriptors, e.g. `400w`. Exactly one of Widths and Densities is set.
+	Widths []int
+	// Densities are the pixel densities of the image, described with `x`
+	// descriptors, e.g. `2x`. The width and height of Transform are the size at
+	// density 1 and are multiplied by each density.
+	Densities []float64
+	// Transform is applied to every image. Its width is replaced by each width
+	// of Widths; its height, if set along with the width, is scaled to keep the
+	// aspect ratio.
+	Transform TransformOptions
+	// Formats are the formats of the image, the preferred first. Each format
+	// but the last one is a `<source>` of the picture, and the last one is the
+	// fallback `<img>`. Defaults to the format picked by the server.
+	Formats []ImageFormat
+	// Sizes is the `sizes` attribute of the picture, e.g. `(max-width: 600px) 100vw, 50vw`.
+	Sizes string
+	// Alt is the `alt` attribute of the image.
+	Alt string
+}
+
+// SrcsetEntry is a candidate image of a srcset.
+type SrcsetEntry struct {
+	URL string
+	// Width is set for a `w` descriptor.
+	Width int
+	// Density is set for an `x` descriptor.
+	Density float64
+}
+
+// Descriptor returns the descriptor of the entry, e.g. `400w` or `2x`.
+func (e SrcsetEntry) Descriptor() string {
+	if e.Width > 0 {
+		return strconv.Itoa(e.Width) + "w"
+	}
+	return strconv.FormatFloat(e.Density, 'f', -1, 64) + "x"
+}
+
+// ImageSource holds the candidate images of a format.
+type ImageSource struct {
+	Format ImageFormat
+	// Type is the MIME type of the format, or an empty string if the format
+	// is left to the server.
+	Type    string
+	Entries []SrcsetEntry
+}
+
+// Srcset returns the `srcset` attribute of the source.
+func (s ImageSource) Srcset() string {
+	candidates := make([]string, len(s.Entries))
+	for i, entry := range s.Entries {
+		candidates[i] = entry.URL + " " + entry.Descriptor()
+	}
+	return strings.Join(candidates, ", ")
+}
+
+// ResponsiveImage is an image served at several sizes and formats.
+type ResponsiveImage struct {
+	// Sources are the formats of the image, in the order of
+	// ResponsiveImageOptions.Formats. The last one is the fallback.
+	Sources []ImageSource
+	Sizes   string
+	Alt     string
+}
+
+// Src returns the URL of the largest image of the fallback format, used as
+// the `src` of the `<img>`.
+func (r ResponsiveImage) Src() string {
+	if len(r.Sources) == 0 {
+		return ""
+	}
+	entries := r.Sources[len(r.Sources)-1].Entries
+	if len(entries) == 0 {
+		return ""
+	}
+	return entries[len(entries)-1].URL
+}
+
+// HTML returns a `<picture>` element with a `<source>` for each format but
+// the fallback, and an `<img>` for the fallback.
+func (r ResponsiveImage) HTML() string {
+	var b strings.Builder
+	b.WriteString("<picture>")
+	for i, source := range r.Sources {
+		if i == len(r.Sources)-1 {
+			break
+		}
+		b.WriteString("<source")
+		writeAttribute(&b, "type", source.Type)
+		writeAttribute(&b, "srcset", source.Srcset())
+		writeAttribute(&b, "sizes", r.Sizes)
+		b.WriteString(">")
+	}
+	b.WriteString("<img")
+	writeAttribute(&b, "src", r.Src())
+	if len(r.Sources) > 0 {
+		writeAttribute(&b, "srcset", r.Sources[len(r.Sources)-1].Srcset())
+	}
+	writeAttribute(&b, "sizes", r.Sizes)
+	b.WriteString(` alt="` + html.EscapeString(r.Alt) + `"`)
+	b.WriteString("></picture>")
+	return b.String()
+}
+
+// writeAttribute writes an escaped attribute, or nothing if value is empty.
+func writeAttribute(b *strings.Builder, name string, value string) {
+	if value == "" {
+		return
+	}
+	b.WriteString(" " + name + `="` + html.EscapeString(value) + `"`)
+}
+
+// GetResponsiveImage returns the public URLs of an image at the widths or
+// densities and formats of options. No request is sent.
+// bucketId string The bucket id
+// filePath string The file path, including the file name. Should be of the format `folder/subfolder/filename.png`
+// options ResponsiveImageOptions The sizes, formats and attributes of the image
+func (c *Client) GetResponsiveImage(bucketId string, filePath string, options ResponsiveImageOptions) (ResponsiveImage, error) {
+	return responsiveImage(options, func(urlOptions UrlOptions) (string, error) {
+		return c.GetPublicUrlWithTransform(bucketId, filePath, *urlOptions.Transform)
+	})
+}
+
+// CreateSignedResponsiveImage is like GetResponsiveImage but returns signed
+// URLs. Each image is signed with its own request, as the transformation is
+// part of the signature.
+// bucketId string The bucket id
+// filePath string The file path, including the file name. Should be of the format `folder/subfolder/filename.png`
+// expiresIn int The number of seconds before the signed URLs expire
+// options ResponsiveImageOptions The sizes, formats and attributes of the image
+func (c *Client) CreateSignedResponsiveImage(bucketId string, filePath string, expiresIn int, options ResponsiveImageOptions) (ResponsiveImage, error) {
+	return c.CreateSignedResponsiveImageWithContext(context.Background(), bucketId, filePath, expiresIn, options)
+}
+
+// CreateSignedResponsiveImageWithContext is like CreateSignedResponsiveImage but carries ctx on the outgoing requests.
+func (c *Client) CreateSignedResponsiveImageWithContext(ctx context.Context, bucketId string, filePath string, expiresIn int, options ResponsiveImageOptions) (ResponsiveImage, error) {
+	return responsiveImage(options, func(urlOptions UrlOptions) (string, error) {
+		response, err := c.CreateSignedUrlWithContext(ctx, bucketId, filePath, expiresIn, urlOptions)
+		return response.SignedURL, err
+	})
+}
+
+// responsiveImage validates every transformation of options before building
+// their URLs with imageUrl.
+func responsiveImage(options ResponsiveImageOptions, imageUrl func(UrlOptions) (string, error)) (ResponsiveImage, error) {
+	variants, err := responsiveVariants(options)
+	if err != nil {
+		return ResponsiveImage{}, err
+	}
+
+	formats := options.Formats
+	if len(formats) == 0 {
+		formats = []ImageFormat{""}
+	}
+	for _, format := range formats {
+		for _, variant := range variants {
+			transform := variant.transform
+			transform.Format = format
+			if err := transform.Validate(); err != nil {
+				return ResponsiveImage{}, err
+			}
+		}
+	}
+
+	image := ResponsiveImage{Sizes: options.Sizes, Alt: options.Alt}
+	for _, format := range formats {
+		source := ImageSource{Format: format, Type: imageFormatTypes[format]}
+		for _, variant := range variants {
+			transform := variant.transform
+			transform.Format = format
+			url, err := imageUrl(UrlOptions{Transform: &transform})
+			if err != nil {
+				return ResponsiveImage{}, err
+			}
+			entry := variant.entry
+			entry.URL = url
+			source.Entries = append(source.Entries, entry)
+		}
+		image.Sources = append(image.Sources, source)
+	}
+	return image, nil
+}
+
+var imageFormatTypes = map[ImageFormat]string{
+	FormatAvif: "image/avif",
+	FormatWebp: "image/webp",
+}
+
+type responsiveVariant struct {
+	entry     SrcsetEntry
+	transform TransformOptions
+}
+
+// responsiveVariants returns the transformation of each width or density of
+// options, from the smallest to the largest.
+func responsiveVariants(options ResponsiveImageOptions) ([]responsiveVariant, error) {
+	base := options.Transform
+	var variants []responsiveVariant
+	switch {
+	case len(options.Widths) > 0 && len(options.Densities) > 0:
+		return nil, errors.New("storage: responsive image has both widths and densities")
+	case len(options.Widths) > 0:
+		widths := append([]int(nil), options.Widths...)
+		sort.Ints(widths)
+		for _, width := range widths {
+			transform := base
+			transform.Width = width
+			if base.Width > 0 && base.Height > 0 {
+				transform.Height = scaleSize(base.Height, float64(width)/float64(base.Width))
+			}
+			variants = append(variants, responsiveVariant{entry: SrcsetEntry{Width: width}, transform: transform})
+		}
+	case len(options.Densities) > 0:
+		if base.Width == 0 && base.Height == 0 {
+			return nil, errors.New("storage: responsive image densities need a width or height")
+		}
+		densities := append([]float64(nil), options.Densities...)
+		sort.Float64s(densities)
+		for _, density := range densities {
+			if density <= 0 {
+				return nil, errors.New("storage: responsive image density " + strconv.FormatFloat(density, 'f', -1, 64) + " is not positive")
+			}
+			transform := base
+			transform.Width = scaleSize(base.Width, density)
+			transform.Height = scaleSize(base.Height, density)
+			variants = append(variants, responsiveVariant{entry: SrcsetEntry{Density: density}, transform: transform})
+		}
+	default:
+		return nil, errors.New("storage: responsive image has no widths or densities")
+	}
+	return variants, nil
+}
+
+func scaleSize(size int, factor float64) int {
+	return int(math.Round(float64(size) * factor))
+}
@@ -0,0 +1,440 @@
+package storage_go
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	tusResumableVersion = "1.0.0"
+	// Supabase Storage requires every chunk except the last to be exactly 6MB.
+	defaultResumableChunkSize = 6 * 1024 * 1024
+)
+
+// FingerprintStore keeps track of the upload URLs of unfinished resumable
+// uploads, keyed by a fingerprint of the upload, so that an interrupted upload
+// can be resumed instead of started over.
+type FingerprintStore interface {
+	// Get returns the upload URL stored for fingerprint, if any.
+	Get(fingerprint string) (uploadURL string, ok bool, err error)
+	// Set stores the upload URL for fingerprint.
+	Set(fingerprint string, uploadURL string) error
+	// Delete removes fingerprint from the store.
+	Delete(fingerprint string) error
+}
+
+// MemoryFingerprintStore is a FingerprintStore that lives as long as the process.
+type MemoryFingerprintStore struct {
+	mu   sync.Mutex
+	urls map[string]string
+}
+
+// NewMemoryFingerprintStore creates an empty in-memory FingerprintStore.
+func NewMemoryFingerprintStore() *MemoryFingerprintStore {
+	return &MemoryFingerprintStore{urls: map[string]string{}}
+}
+
+func (s *MemoryFingerprintStore) Get(fingerprint string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	uploadURL, ok := s.urls[fingerprint]
+	return uploadURL, ok, nil
+}
+
+func (s *MemoryFingerprintStore) Set(fingerprint string, uploadURL string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.urls[fingerprint] = uploadURL
+	return nil
+}
+
+func (s *MemoryFingerprintStore) Delete(fingerprint string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.urls, fingerprint)
+	return nil
+}
+
+// FileFingerprintStore is a FingerprintStore persisted as a JSON file, so
+// uploads can be resumed after the process restarts.
+type FileFingerprintStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileFingerprintStore creates a FingerprintStore backed by the file at path.
+// The file is created on the first Set.
+func NewFileFingerprintStore(path string) *FileFingerprintStore {
+	return &FileFingerprintStore{path: path}
+}
+
+func (s *FileFingerprintStore) Get(fingerprint string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	urls, err := s.load()
+	if err != nil {
+		return "", false, err
+	}
+	uploadURL, ok := urls[fingerprint]
+	return uploadURL, ok, nil
+}
+
+func (s *FileFingerprintStore) Set(fingerprint string, uploadURL string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	urls, err := s.load()
+	if err != nil {
+		return err
+	}
+	urls[fingerprint] = uploadURL
+	return s.save(urls)
+}
+
+func (s *FileFingerprintStore) Delete(fingerprint string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	urls, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := urls[fingerprint]; !ok {
+		return nil
+	}
+	delete(urls, fingerprint)
+	return s.save(urls)
+}
+
+func (s *FileFingerprintStore) load() (map[string]string, error) {
+	urls := map[string]string{}
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return urls, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return urls, nil
+	}
+	if err := json.Unmarshal(data, &urls); err != nil {
+		return nil, err
+	}
+	return urls, nil
+}
+
+// save writes urls to a temporary file first so a crash never leaves a
+// truncated store behind.
+func (s *FileFingerprintStore) save(urls map[string]string) error {
+	data, err := json.Marshal(urls)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// ResumableUploadOptions configures UploadResumable.
+type ResumableUploadOptions struct {
+	FileOptions
+	// ChunkSize is the number of bytes sent per PATCH request. Defaults to 6MB,
+	// which is what Supabase Storage expects.
+	ChunkSize int64
+	// Store records unfinished uploads so they can be resumed by a later call.
+	// When nil, an interrupted upload has to start over.
+	Store FingerprintStore
+	// Fingerprint identifies the upload in Store. Defaults to the bucket id,
+	// path and size of the file.
+	Fingerprint string
+	// OnProgress is called after every chunk with the number of bytes the
+	// server has acknowledged so far and the total size.
+	OnProgress func(bytesUploaded int64, bytesTotal int64)
+}
+
+// UploadResumable uploads a file using the TUS resumable upload protocol.
+// bucketId string The bucket id
+// relativePath path The file path, including the file name. Should be of the format `folder/subfolder/filename.png`
+// data io.ReadSeeker The file data, from its current position. It is seeked to resume an interrupted upload.
+// options ResumableUploadOptions The chunk size, fingerprint store and progress callback
+func (c *Client) UploadResumable(bucketId string, relativePath string, data io.ReadSeeker, options ...ResumableUploadOptions) (FileUploadResponse, error) {
+	return c.UploadResumableWithContext(context.Background(), bucketId, relativePath, data, options...)
+}
+
+// UploadResumableWithContext is like UploadResumable but carries ctx on the outgoing requests.
+func (c *Client) UploadResumableWithContext(ctx context.Context, bucketId string, relativePath string, data io.ReadSeeker, options ...ResumableUploadOptions) (FileUploadResponse, error) {
+	var opts ResumableUploadOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = defaultResumableChunkSize
+	}
+
+	// The upload starts at the current position of data, like UploadFile.
+	start, err := data.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return FileUploadResponse{}, err
+	}
+	end, err := data.Seek(0, io.SeekEnd)
+	if err != nil {
+		return FileUploadResponse{}, err
+	}
+	size := end - start
+
+	objectName := removeEmptyFolderName(relativePath)
+	fingerprint := opts.Fingerprint
+	if fingerprint == "" {
+		fingerprint = fmt.Sprintf("%s/%s:%d", bucketId, objectName, size)
+	}
+
+	var uploadURL string
+	var offset int64
+	if opts.Store != nil {
+		storedURL, ok, err := opts.Store.Get(fingerprint)
+		if err != nil {
+			return FileUploadResponse{}, err
+		}
+		if ok {
+			offset, err = c.resumableUploadOffset(ctx, storedURL)
+			switch {
+			case err == nil:
+				uploadURL = storedURL
+			case errors.Is(err, errResumableUploadGone):
+				// The server has forgotten the upload, start a new one.
+				offset = 0
+			default:
+				return FileUploadResponse{}, err
+			}
+		}
+	}
+
+	if uploadURL == "" {
+		uploadURL, err = c.createResumableUpload(ctx, bucketId, objectName, size, opts.FileOptions)
+		if err != nil {
+			return FileUploadResponse{}, err
+		}
+		if opts.Store != nil {
+			if err := opts.Store.Set(fingerprint, uploadURL); err != nil {
+				return FileUploadResponse{}, err
+			}
+		}
+	}
+
+	if offset > size {
+		return FileUploadResponse{}, fmt.Errorf("storage: resumable upload: server offset %d exceeds file size %d", offset, size)
+	}
+	if opts.OnProgress != nil {
+		opts.OnProgress(offset, size)
+	}
+
+	if _, err := data.Seek(start+offset, io.SeekStart); err != nil {
+		return FileUploadResponse{}, err
+	}
+	chunk := make([]byte, minInt64(opts.ChunkSize, size-offset))
+	// resynced is set when the offset was read back from the server after a
+	// failed chunk, so a chunk that keeps failing ends the upload.
+	resynced := false
+	for offset < size {
+		n, err := io.ReadFull(data, chunk[:minInt64(int64(len(chunk)), size-offset)])
+		if err != nil {
+			return FileUploadResponse{}, err
+		}
+		newOffset, err := c.patchResumableUpload(ctx, uploadURL, offset, chunk[:n])
+		if err != nil {
+			if resynced || !needsOffsetResync(ctx, err) {
+				return FileUploadResponse{}, err
+			}
+			// The server may have applied the chunk and the answer been lost,
+			// in which case a retry of the chunk is rejected with a conflict.
+			newOffset, err = c.resumableUploadOffset(ctx, uploadURL)
+			if err != nil {
+				return FileUploadResponse{}, err
+			}
+			resynced = newOffset == offset
+		} else {
+			resynced = false
+		}
+		if newOffset < offset || newOffset > size || (newOffset == offset && !resynced) {
+			return FileUploadResponse{}, fmt.Errorf("storage: resumable upload: server returned offset %d after sending %d bytes at offset %d", newOffset, n, offset)
+		}
+		// The server may have accepted only part of the chunk, or none of it.
+		if newOffset != offset+int64(n) {
+			if _, err := data.Seek(start+newOffset, io.SeekStart); err != nil {
+				return FileUploadResponse{}, err
+			}
+		}
+		offset = newOffset
+		if opts.OnProgress != nil {
+			opts.OnProgress(offset, size)
+		}
+	}
+
+	if opts.Store != nil {
+		if err := opts.Store.Delete(fingerprint); err != nil {
+			return FileUploadResponse{}, err
+		}
+	}
+
+	return FileUploadResponse{Key: bucketId + "/" + objectName}, nil
+}
+
+// errResumableUploadGone reports that the server no longer knows a stored upload URL.
+var errResumableUploadGone = errors.New("storage: resumable upload: upload no longer exists")
+
+// createResumableUpload creates a new upload on the server and returns its URL.
+func (c *Client) createResumableUpload(ctx context.Context, bucketId string, objectName string, size int64, options FileOptions) (string, error) {
+	createURL := c.clientTransport.baseUrl.String() + "/upload/resumable"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, createURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	contentType := defaultFileContentType
+	if options.ContentType != nil {
+		contentType = *options.ContentType
+	}
+	cacheControl := defaultFileCacheControl
+	if options.CacheControl != nil {
+		cacheControl = *options.CacheControl
+	}
+	upsert := defaultFileUpsert
+	if options.Upsert != nil {
+		upsert = *options.Upsert
+	}
+
+	req.Header.Set("Tus-Resumable", tusResumableVersion)
+	req.Header.Set("Upload-Length", strconv.FormatInt(size, 10))
+	pairs := [][2]string{
+		{"bucketName", bucketId},
+		{"objectName", objectName},
+		{"contentType", contentType},
+		{"cacheControl", cacheControl},
+	}
+	if len(options.MetadataJSON) > 0 {
+		pairs = append(pairs, [2]string{"metadata", string(options.MetadataJSON)})
+	} else if options.Metadata != nil {
+		metadata, _ := json.Marshal(options.Metadata)
+		pairs = append(pairs, [2]string{"metadata", string(metadata)})
+	}
+	req.Header.Set("Upload-Metadata", encodeUploadMetadata(pairs))
+	req.Header.Set("x-upsert", strconv.FormatBool(upsert))
+
+	resp, err := c.Do(req, nil)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return "", err
+	}
+
+	location, err := resp.Location()
+	if err != nil {
+		return "", fmt.Errorf("storage: resumable upload: created without a location: %w", err)
+	}
+
+	return location.String(), nil
+}
+
+// resumableUploadOffset asks the server how many bytes of the upload it has received.
+func (c *Client) resumableUploadOffset(ctx context.Context, uploadURL string) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, uploadURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Tus-Resumable", tusResumableVersion)
+
+	resp, err := c.Do(req, nil)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		if resp != nil {
+			switch resp.StatusCode {
+			case http.StatusNotFound, http.StatusGone, http.StatusForbidden:
+				return 0, errResumableUploadGone
+			}
+		}
+		return 0, err
+	}
+
+	return parseUploadOffset(resp)
+}
+
+// patchResumableUpload sends chunk at offset and returns the new offset.
+func (c *Client) patchResumableUpload(ctx context.Context, uploadURL string, offset int64, chunk []byte) (int64, error) {
+	// A PATCH carries its offset, so the server rejects a retry of a chunk it already has.
+	req, err := http.NewRequestWithContext(withIdempotent(ctx), http.MethodPatch, uploadURL, bytes.NewReader(chunk))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Tus-Resumable", tusResumableVersion)
+	req.Header.Set("Upload-Offset", strconv.FormatInt(offset, 10))
+	req.Header.Set("Content-Type", "application/offset+octet-stream")
+
+	resp, err := c.Do(req, nil)
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return parseUploadOffset(resp)
+}
+
+// needsOffsetResync reports whether a failed PATCH may have been applied by
+// the server: its answer was lost, or a retry was rejected because the
+// offset moved.
+func needsOffsetResync(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var storageErr *StorageError
+	return errors.Is(err, ErrConflict) || !errors.As(err, &storageErr)
+}
+
+func parseUploadOffset(resp *http.Response) (int64, error) {
+	offset, err := strconv.ParseInt(resp.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("storage: resumable upload: invalid Upload-Offset header: %w", err)
+	}
+	return offset, nil
+}
+
+// encodeUploadMetadata encodes pairs as a TUS Upload-Metadata header value.
+func encodeUploadMetadata(pairs [][2]string) string {
+	encoded := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		encoded = append(encoded, pair[0]+" "+base64.StdEncoding.EncodeToString([]byte(pair[1])))
+	}
+	return strings.Join(encoded, ",")
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,187 @@
+package storage_go
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how Do retries a request that failed with a network
+// error or a retryable status code. Only idempotent requests are retried, and
+// only when their body can be rewound: no body, an in-memory body, or an
+// io.Seeker passed to an upload.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// A value of 1 or less disables retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry. It doubles on every
+	// following retry, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Jitter is the fraction of each delay, between 0 and 1, that is randomized
+	// so that many clients do not retry in lockstep.
+	Jitter float64
+	// RetryableStatusCodes are the response status codes that are retried.
+	RetryableStatusCodes []int
+	// MaxRetryAfter is the longest delay asked by a Retry-After header that is
+	// waited for. A response asking for a longer delay is not retried, and its
+	// error is returned. Defaults to a minute. It is independent of MaxBackoff,
+	// which only caps the delays the policy computes itself.
+	MaxRetryAfter time.Duration
+}
+
+const defaultMaxRetryAfter = time.Minute
+
+// DefaultRetryPolicy is the retry policy of a new Client.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 200 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	MaxRetryAfter:  defaultMaxRetryAfter,
+	Jitter:         0.2,
+	RetryableStatusCodes: []int{
+		http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+}
+
+// NoRetry is a RetryPolicy that makes a single attempt.
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// SetRetryPolicy replaces the retry policy of the client. It must be called
+// before the client is used from multiple goroutines.
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.retryPolicy = policy
+}
+
+type retryPolicyContextKey struct{}
+
+// ContextWithRetryPolicy returns a context that makes the requests it is
+// passed to use policy instead of the retry policy of the client.
+func ContextWithRetryPolicy(ctx context.Context, policy RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyContextKey{}, policy)
+}
+
+type idempotentContextKey struct{}
+
+// withIdempotent marks a request that is safe to retry even though its method
+// is not idempotent, such as a POST that lists files or uploads a file.
+func withIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentContextKey{}, true)
+}
+
+// retryPolicyFor returns the retry policy that applies to req.
+func (c *Client) retryPolicyFor(req *http.Request) RetryPolicy {
+	if policy, ok := req.Context().Value(retryPolicyContextKey{}).(RetryPolicy); ok {
+		return policy
+	}
+	return c.retryPolicy
+}
+
+// isRetryable reports whether req may be sent more than once.
+func isRetryable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		if marked, _ := req.Context().Value(idempotentContextKey{}).(bool); !marked {
+			return false
+		}
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// shouldRetry reports whether an attempt that ended with resp and err must be
+// retried, and how long to wait before doing so.
+func (p RetryPolicy) shouldRetry(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts || req.Context().Err() != nil {
+		return 0, false
+	}
+	if err != nil {
+		return p.backoff(attempt), true
+	}
+
+	retryable := false
+	for _, code := range p.RetryableStatusCodes {
+		if resp.StatusCode == code {
+			retryable = true
+			break
+		}
+	}
+	if !retryable {
+		return 0, false
+	}
+
+	delay := p.backoff(attempt)
+	if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok && retryAfter > delay {
+		if retryAfter > p.maxRetryAfter() {
+			return 0, false
+		}
+		delay = retryAfter
+	}
+	return delay, true
+}
+
+func (p RetryPolicy) maxRetryAfter() time.Duration {
+	if p.MaxRetryAfter > 0 {
+		return p.MaxRetryAfter
+	}
+	return defaultMaxRetryAfter
+}
+
+// backoff returns the delay before retry number attempt, starting at 1.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || delay < p.MaxBackoff); i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if p.Jitter > 0 && delay > 0 {
+		jitter := time.Duration(p.Jitter * float64(delay))
+		delay = delay - jitter + time.Duration(rand.Int63n(int64(jitter)+1))
+	}
+	return delay
+}
+
+// parseRetryAfter parses a Retry-After header holding either seconds or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// sleepContext waits for delay or until ctx is done.
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// discardBody drains and closes the body of a response that is not used, so
+// the connection can be reused.
+func discardBody(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	resp.Body.Close()
+}
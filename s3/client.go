@@ -0,0 +1,180 @@
+// Package s3 is a client for the S3 protocol endpoint of Supabase Storage
+// (`/storage/v1/s3`). Requests are signed with AWS Signature Version 4 using
+// only the standard library.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	storage_go "github.com/supabase-community/storage-go"
+)
+
+// Client talks to the S3 protocol endpoint of a Supabase project. A Client is
+// safe for concurrent use by multiple goroutines.
+type Client struct {
+	endpoint url.URL
+	signer   Signer
+	session  *http.Client
+	now      func() time.Time
+}
+
+// Option configures a Client created with NewClient.
+type Option func(*Client)
+
+// WithHTTPClient makes the Client send requests with httpClient instead of
+// http.DefaultClient. Use it to set a timeout, a proxy or a custom transport.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.session = httpClient
+	}
+}
+
+// NewClient creates a client for endpoint, e.g.
+// `https://<project-reference-id>.supabase.co/storage/v1/s3`. region is the
+// region of the project.
+func NewClient(endpoint string, region string, credentials Credentials, options ...Option) (*Client, error) {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if endpointURL.Scheme == "" || endpointURL.Host == "" {
+		return nil, fmt.Errorf("s3: endpoint %q must be an absolute URL", endpoint)
+	}
+	endpointURL.Path = strings.TrimSuffix(endpointURL.Path, "/")
+
+	c := &Client{
+		endpoint: *endpointURL,
+		signer: Signer{
+			Credentials: credentials,
+			Region:      region,
+			Service:     "s3",
+		},
+		session: http.DefaultClient,
+		now:     time.Now,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	if c.session == nil {
+		c.session = http.DefaultClient
+	}
+	return c, nil
+}
+
+// Error is returned when the endpoint answers with an S3 error document.
+type Error struct {
+	StatusCode int    `xml:"-"`
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+	Resource   string `xml:"Resource"`
+	RequestID  string `xml:"RequestId"`
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("s3: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("s3: %s: %s", e.Code, e.Message)
+}
+
+// Is lets errors.Is match e against the sentinel errors of storage_go, such
+// as storage_go.ErrNotFound.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case storage_go.ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case storage_go.ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case storage_go.ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case storage_go.ErrPayloadTooLarge:
+		return e.StatusCode == http.StatusRequestEntityTooLarge
+	}
+	return false
+}
+
+// objectURL returns the path-style URL of key in bucket, with query appended.
+func (c *Client) objectURL(bucket string, key string, query url.Values) *url.URL {
+	u := c.endpoint
+	u.Path = c.endpoint.Path + "/" + bucket
+	if key != "" {
+		u.Path += "/" + key
+	}
+	u.RawQuery = query.Encode()
+	return &u
+}
+
+// newRequest creates a request for bucket and key. body must be nil or
+// seekable so its SHA-256 can be included in the signature.
+func (c *Client) newRequest(ctx context.Context, method string, bucket string, key string, query url.Values, body io.ReadSeeker) (*http.Request, string, error) {
+	payloadHash := EmptyPayloadHash
+	var reqBody io.Reader
+	var contentLength int64
+	if body != nil {
+		hash := sha256.New()
+		n, err := io.Copy(hash, body)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := body.Seek(-n, io.SeekCurrent); err != nil {
+			return nil, "", err
+		}
+		payloadHash = hex.EncodeToString(hash.Sum(nil))
+		reqBody = io.NopCloser(body)
+		contentLength = n
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.objectURL(bucket, key, query).String(), reqBody)
+	if err != nil {
+		return nil, "", err
+	}
+	req.ContentLength = contentLength
+	if contentLength == 0 {
+		req.Body = http.NoBody
+	}
+
+	return req, payloadHash, nil
+}
+
+// do signs and sends req. If v is not nil the XML response is decoded into it.
+func (c *Client) do(req *http.Request, payloadHash string, v interface{}) (*http.Response, error) {
+	c.signer.Sign(req, payloadHash, c.now())
+
+	resp, err := c.session.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return resp, parseError(resp)
+	}
+
+	if v != nil {
+		defer resp.Body.Close()
+		if err := xml.NewDecoder(resp.Body).Decode(v); err != nil {
+			return resp, err
+		}
+	}
+
+	return resp, nil
+}
+
+func parseError(resp *http.Response) error {
+	s3Err := &Error{StatusCode: resp.StatusCode}
+	data, err := io.ReadAll(resp.Body)
+	if err == nil && len(bytes.TrimSpace(data)) > 0 {
+		_ = xml.Unmarshal(data, s3Err)
+	}
+	return s3Err
+}
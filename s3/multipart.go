@@ -0,0 +1,107 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// CreateMultipartUploadOutput is the result of CreateMultipartUpload.
+type CreateMultipartUploadOutput struct {
+	Bucket   string `xml:"Bucket"`
+	Key      string `xml:"Key"`
+	UploadId string `xml:"UploadId"`
+}
+
+// CompletedPart identifies an uploaded part in CompleteMultipartUpload.
+type CompletedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// CompleteMultipartUploadOutput is the result of CompleteMultipartUpload.
+type CompleteMultipartUploadOutput struct {
+	Location string `xml:"Location"`
+	Bucket   string `xml:"Bucket"`
+	Key      string `xml:"Key"`
+	ETag     string `xml:"ETag"`
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []CompletedPart `xml:"Part"`
+}
+
+// CreateMultipartUpload starts a multipart upload of key in bucket.
+func (c *Client) CreateMultipartUpload(ctx context.Context, bucket string, key string, options *PutObjectOptions) (*CreateMultipartUploadOutput, error) {
+	req, payloadHash, err := c.newRequest(ctx, http.MethodPost, bucket, key, url.Values{"uploads": {""}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	options.apply(req)
+
+	var output CreateMultipartUploadOutput
+	if _, err := c.do(req, payloadHash, &output); err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// UploadPart uploads body as part partNumber (1 to 10000) of the upload.
+func (c *Client) UploadPart(ctx context.Context, bucket string, key string, uploadId string, partNumber int, body io.ReadSeeker) (CompletedPart, error) {
+	query := url.Values{
+		"partNumber": {strconv.Itoa(partNumber)},
+		"uploadId":   {uploadId},
+	}
+	req, payloadHash, err := c.newRequest(ctx, http.MethodPut, bucket, key, query, body)
+	if err != nil {
+		return CompletedPart{}, err
+	}
+
+	resp, err := c.do(req, payloadHash, nil)
+	if err != nil {
+		return CompletedPart{}, err
+	}
+	resp.Body.Close()
+
+	return CompletedPart{PartNumber: partNumber, ETag: resp.Header.Get("ETag")}, nil
+}
+
+// CompleteMultipartUpload assembles the uploaded parts into the final object.
+func (c *Client) CompleteMultipartUpload(ctx context.Context, bucket string, key string, uploadId string, parts []CompletedPart) (*CompleteMultipartUploadOutput, error) {
+	body, err := xml.Marshal(completeMultipartUpload{Parts: parts})
+	if err != nil {
+		return nil, err
+	}
+	req, payloadHash, err := c.newRequest(ctx, http.MethodPost, bucket, key, url.Values{"uploadId": {uploadId}}, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/xml")
+
+	var output CompleteMultipartUploadOutput
+	if _, err := c.do(req, payloadHash, &output); err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// AbortMultipartUpload cancels the upload and discards the uploaded parts.
+func (c *Client) AbortMultipartUpload(ctx context.Context, bucket string, key string, uploadId string) error {
+	req, payloadHash, err := c.newRequest(ctx, http.MethodDelete, bucket, key, url.Values{"uploadId": {uploadId}}, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(req, payloadHash, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
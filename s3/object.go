@@ -0,0 +1,203 @@
+package s3
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	storage_go "github.com/supabase-community/storage-go"
+)
+
+// PutObjectOptions are the optional headers of PutObject and CreateMultipartUpload.
+type PutObjectOptions struct {
+	ContentType  string
+	CacheControl string
+}
+
+func (o *PutObjectOptions) apply(req *http.Request) {
+	if o == nil {
+		return
+	}
+	if o.ContentType != "" {
+		req.Header.Set("Content-Type", o.ContentType)
+	}
+	if o.CacheControl != "" {
+		req.Header.Set("Cache-Control", o.CacheControl)
+	}
+}
+
+// PutObjectOutput is the result of PutObject.
+type PutObjectOutput struct {
+	ETag string
+}
+
+// PutObject uploads body as key in bucket in a single request.
+func (c *Client) PutObject(ctx context.Context, bucket string, key string, body io.ReadSeeker, options *PutObjectOptions) (PutObjectOutput, error) {
+	req, payloadHash, err := c.newRequest(ctx, http.MethodPut, bucket, key, nil, body)
+	if err != nil {
+		return PutObjectOutput{}, err
+	}
+	options.apply(req)
+
+	resp, err := c.do(req, payloadHash, nil)
+	if err != nil {
+		return PutObjectOutput{}, err
+	}
+	resp.Body.Close()
+
+	return PutObjectOutput{ETag: resp.Header.Get("ETag")}, nil
+}
+
+// GetObjectOptions are the optional parameters of GetObject.
+type GetObjectOptions struct {
+	// Range is an HTTP Range header value, e.g. `bytes=0-1023`.
+	Range string
+}
+
+// GetObjectOutput is the result of GetObject. Body must be closed by the caller.
+type GetObjectOutput struct {
+	Body          io.ReadCloser
+	ContentLength int64
+	ContentType   string
+	ContentRange  string
+	ETag          string
+	LastModified  time.Time
+}
+
+// GetObject downloads key from bucket, or the part of it selected by options.Range.
+func (c *Client) GetObject(ctx context.Context, bucket string, key string, options *GetObjectOptions) (*GetObjectOutput, error) {
+	req, payloadHash, err := c.newRequest(ctx, http.MethodGet, bucket, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if options != nil && options.Range != "" {
+		req.Header.Set("Range", options.Range)
+	}
+
+	resp, err := c.do(req, payloadHash, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	lastModified, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return &GetObjectOutput{
+		Body:          resp.Body,
+		ContentLength: resp.ContentLength,
+		ContentType:   resp.Header.Get("Content-Type"),
+		ContentRange:  resp.Header.Get("Content-Range"),
+		ETag:          resp.Header.Get("ETag"),
+		LastModified:  lastModified,
+	}, nil
+}
+
+type listAllMyBucketsResult struct {
+	Buckets []struct {
+		Name         string    `xml:"Name"`
+		CreationDate time.Time `xml:"CreationDate"`
+	} `xml:"Buckets>Bucket"`
+}
+
+// ListBuckets lists the buckets of the project.
+func (c *Client) ListBuckets(ctx context.Context) ([]storage_go.Bucket, error) {
+	req, payloadHash, err := c.newRequest(ctx, http.MethodGet, "", "", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result listAllMyBucketsResult
+	if _, err := c.do(req, payloadHash, &result); err != nil {
+		return nil, err
+	}
+
+	buckets := make([]storage_go.Bucket, 0, len(result.Buckets))
+	for _, b := range result.Buckets {
+		buckets = append(buckets, storage_go.Bucket{
+			Id:        b.Name,
+			Name:      b.Name,
+			CreatedAt: b.CreationDate,
+		})
+	}
+	return buckets, nil
+}
+
+// Object is an entry of ListObjectsV2.
+type Object struct {
+	Key          string    `xml:"Key"`
+	LastModified time.Time `xml:"LastModified"`
+	ETag         string    `xml:"ETag"`
+	Size         int64     `xml:"Size"`
+	StorageClass string    `xml:"StorageClass"`
+}
+
+// FileObject converts o to the FileObject returned by the Storage API client.
+func (o Object) FileObject(bucket string) storage_go.FileObject {
+	return storage_go.FileObject{
+		Name:      o.Key,
+		BucketId:  bucket,
+		UpdatedAt: o.LastModified,
+		Metadata: storage_go.ObjectMetadata{
+			ETag:          o.ETag,
+			Size:          o.Size,
+			LastModified:  o.LastModified,
+			ContentLength: o.Size,
+		},
+	}
+}
+
+// ListObjectsV2Input are the parameters of ListObjectsV2.
+type ListObjectsV2Input struct {
+	Prefix            string
+	Delimiter         string
+	MaxKeys           int
+	ContinuationToken string
+	StartAfter        string
+}
+
+// ListObjectsV2Output is one page of ListObjectsV2.
+type ListObjectsV2Output struct {
+	Name                  string   `xml:"Name"`
+	Prefix                string   `xml:"Prefix"`
+	KeyCount              int      `xml:"KeyCount"`
+	MaxKeys               int      `xml:"MaxKeys"`
+	IsTruncated           bool     `xml:"IsTruncated"`
+	ContinuationToken     string   `xml:"ContinuationToken"`
+	NextContinuationToken string   `xml:"NextContinuationToken"`
+	Contents              []Object `xml:"Contents"`
+	CommonPrefixes        []string `xml:"CommonPrefixes>Prefix"`
+}
+
+// ListObjectsV2 lists one page of the objects in bucket. Pass the
+// NextContinuationToken of a truncated page as input.ContinuationToken to get
+// the next one.
+func (c *Client) ListObjectsV2(ctx context.Context, bucket string, input ListObjectsV2Input) (*ListObjectsV2Output, error) {
+	query := url.Values{"list-type": {"2"}}
+	if input.Prefix != "" {
+		query.Set("prefix", input.Prefix)
+	}
+	if input.Delimiter != "" {
+		query.Set("delimiter", input.Delimiter)
+	}
+	if input.MaxKeys > 0 {
+		query.Set("max-keys", strconv.Itoa(input.MaxKeys))
+	}
+	if input.ContinuationToken != "" {
+		query.Set("continuation-token", input.ContinuationToken)
+	}
+	if input.StartAfter != "" {
+		query.Set("start-after", input.StartAfter)
+	}
+
+	req, payloadHash, err := c.newRequest(ctx, http.MethodGet, bucket, "", query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var output ListObjectsV2Output
+	if _, err := c.do(req, payloadHash, &output); err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
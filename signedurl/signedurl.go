@@ -0,0 +1,177 @@
+// Package signedurl verifies the signed URLs of Supabase Storage in your own
+// servers, e.g. to proxy downloads. Tokens are verified with the JWT secret
+// of the project alone:
+//
+//	verifier := signedurl.New(jwtSecret)
+//	handler := verifier.Middleware(signedurl.Options{Prefix: "/downloads/"})(downloads)
+//
+// The claims of a verified token are available to the handler with
+// ClaimsFromContext.
+package signedurl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	storage_go "github.com/supabase-community/storage-go"
+)
+
+var (
+	// ErrMissingToken is returned for a URL or request without a token.
+	ErrMissingToken = errors.New("signedurl: missing token")
+	// ErrObjectMismatch is returned for a valid token that was signed for
+	// another object than the requested one.
+	ErrObjectMismatch = errors.New("signedurl: token was not signed for the requested object")
+)
+
+// signedRoutes are the routes of the signed URLs of the server.
+var signedRoutes = []string{"/object/sign/", "/render/image/sign/"}
+
+// Verifier verifies signed URLs and their tokens. It is safe for concurrent use.
+type Verifier struct {
+	signer *storage_go.LocalSigner
+}
+
+// New creates a verifier from the JWT secret of the project.
+// jwtSecret string The JWT secret of the project
+func New(jwtSecret string) *Verifier {
+	return &Verifier{signer: storage_go.NewLocalSigner(jwtSecret)}
+}
+
+// Verify parses a signed URL created by the server or a LocalSigner, and
+// verifies its token against the object of its path.
+// signedURL string The signed URL, e.g. `https://<project>.supabase.co/storage/v1/object/sign/bucket-id/filename.png?token=...`
+func (v *Verifier) Verify(signedURL string) (storage_go.SignedUrlClaims, error) {
+	u, err := url.Parse(signedURL)
+	if err != nil {
+		return storage_go.SignedUrlClaims{}, err
+	}
+	for _, route := range signedRoutes {
+		if i := strings.Index(u.Path, route); i >= 0 {
+			bucketId, filePath := splitObject(u.Path[i+len(route):])
+			claims, err := v.VerifyToken(u.Query().Get("token"), bucketId, filePath)
+			if err == nil && (claims.Transformations != "") != (route == "/render/image/sign/") {
+				return claims, ErrObjectMismatch
+			}
+			return claims, err
+		}
+	}
+	return storage_go.SignedUrlClaims{}, errors.New("signedurl: " + signedURL + " is not a signed URL")
+}
+
+// VerifyToken verifies a token and checks that it was signed for an object.
+// The error is ErrMissingToken, ErrObjectMismatch, storage_go.ErrInvalidToken
+// or storage_go.ErrTokenExpired.
+// token string The token of the signed URL
+// bucketId string The bucket id of the requested object
+// filePath string The path of the requested object
+func (v *Verifier) VerifyToken(token string, bucketId string, filePath string) (storage_go.SignedUrlClaims, error) {
+	if token == "" {
+		return storage_go.SignedUrlClaims{}, ErrMissingToken
+	}
+	claims, err := v.signer.Verify(token)
+	if err != nil {
+		return claims, err
+	}
+	if !claims.IsFor(bucketId, filePath) {
+		return claims, ErrObjectMismatch
+	}
+	return claims, nil
+}
+
+// Options configures Verifier.Middleware.
+type Options struct {
+	// Prefix is removed from the path of a request to get the requested
+	// object, `bucket-id/folder/filename.png`. Defaults to "/".
+	Prefix string
+	// Object returns the requested object of a request, or false if the
+	// request has none. It replaces Prefix.
+	Object func(r *http.Request) (bucketId string, filePath string, ok bool)
+	// ErrorHandler writes the response to a rejected request. Defaults to a
+	// 400 response with the error body of the server.
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// Middleware returns a middleware that only passes requests with a valid
+// `token` query parameter to the next handler. The claims of the token are
+// stored in the context of the request.
+// options Options The object of the requests and the error response
+func (v *Verifier) Middleware(options ...Options) func(http.Handler) http.Handler {
+	var opts Options
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	if opts.Prefix == "" {
+		opts.Prefix = "/"
+	}
+	if opts.Object == nil {
+		prefix := opts.Prefix
+		opts.Object = func(r *http.Request) (string, string, bool) {
+			if !strings.HasPrefix(r.URL.Path, prefix) {
+				return "", "", false
+			}
+			bucketId, filePath := splitObject(strings.TrimPrefix(r.URL.Path, prefix))
+			return bucketId, filePath, bucketId != ""
+		}
+	}
+	if opts.ErrorHandler == nil {
+		opts.ErrorHandler = writeError
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			bucketId, filePath, ok := opts.Object(r)
+			if !ok {
+				opts.ErrorHandler(w, r, ErrObjectMismatch)
+				return
+			}
+			claims, err := v.VerifyToken(r.URL.Query().Get("token"), bucketId, filePath)
+			if err != nil {
+				opts.ErrorHandler(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), claimsKey{}, claims)))
+		})
+	}
+}
+
+type claimsKey struct{}
+
+// ClaimsFromContext returns the claims stored by Verifier.Middleware.
+func ClaimsFromContext(ctx context.Context) (storage_go.SignedUrlClaims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(storage_go.SignedUrlClaims)
+	return claims, ok
+}
+
+// writeError answers like the server does for a rejected signed URL.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	message := err.Error()
+	switch {
+	case errors.Is(err, storage_go.ErrTokenExpired):
+		message = "jwt expired"
+	case errors.Is(err, storage_go.ErrInvalidToken):
+		message = "invalid signature"
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"statusCode": strconv.Itoa(http.StatusBadRequest),
+		"error":      "InvalidJWT",
+		"message":    message,
+	})
+}
+
+// splitObject splits `bucket-id/folder/filename.png` into a bucket id and a
+// file path.
+func splitObject(object string) (string, string) {
+	i := strings.Index(object, "/")
+	if i < 0 {
+		return object, ""
+	}
+	return object[:i], object[i+1:]
+}
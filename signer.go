@@ -0,0 +1,243 @@
+package storage_go
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidToken is returned by LocalSigner.Verify for a token that is
+	// malformed or not signed with the secret of the signer.
+	ErrInvalidToken = errors.New("storage: invalid signed URL token")
+	// ErrTokenExpired is returned by LocalSigner.Verify for a token whose exp
+	// claim is in the past.
+	ErrTokenExpired = errors.New("storage: signed URL token expired")
+)
+
+// SignedUrlClaims are the claims of the token of a signed URL.
+type SignedUrlClaims struct {
+	// URL is the signed object, e.g. `bucket-id/folder/filename.png`.
+	URL string `json:"url"`
+	// Transformations are the image transformations of the URL, if any, in
+	// the format of the server, e.g. `width:200,resize:cover`.
+	Transformations string `json:"transformations,omitempty"`
+	// IssuedAt and ExpiresAt are Unix times, in seconds.
+	IssuedAt  int64 `json:"iat"`
+	ExpiresAt int64 `json:"exp"`
+}
+
+// IsFor reports whether the claims were signed for the object at filePath in
+// bucketId.
+func (c SignedUrlClaims) IsFor(bucketId string, filePath string) bool {
+	_, claim := signedObjectPath(bucketId, filePath)
+	return c.URL == claim
+}
+
+// BucketAndPath splits the URL claim into a bucket id and a file path. Like
+// the claim, the file path keeps the escapes of reserved characters, e.g.
+// `%3F` for `?`.
+func (c SignedUrlClaims) BucketAndPath() (bucketId string, filePath string) {
+	i := strings.Index(c.URL, "/")
+	if i < 0 {
+		return c.URL, ""
+	}
+	return c.URL[:i], c.URL[i+1:]
+}
+
+// LocalSigner creates and verifies the tokens of signed URLs without calling
+// the server, with the JWT secret of the project. The tokens are HS256 JWTs
+// with the claims of the tokens of the server, so the URLs are accepted by the
+// server and by Verify. It is safe for concurrent use.
+type LocalSigner struct {
+	secret  []byte
+	baseUrl string
+	now     func() time.Time
+}
+
+// LocalSignerOptions configures a LocalSigner.
+type LocalSignerOptions struct {
+	// BaseUrl is the URL of the Storage API, as passed to NewClient, e.g.
+	// `https://<project>.supabase.co/storage/v1`. CreateSignedUrl returns URLs
+	// relative to it when it is empty. Verifying tokens does not need it.
+	BaseUrl string
+	// Now returns the current time, used for the iat and exp claims and to
+	// check expiry. Defaults to time.Now.
+	Now func() time.Time
+}
+
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// NewLocalSigner creates a signer from the JWT secret of the project.
+// jwtSecret string The JWT secret of the project. Keep it on the server: anyone holding it can sign URLs for any object.
+// options LocalSignerOptions The base URL of the signed URLs
+func NewLocalSigner(jwtSecret string, options ...LocalSignerOptions) *LocalSigner {
+	s := &LocalSigner{secret: []byte(jwtSecret), now: time.Now}
+	if len(options) > 0 {
+		s.baseUrl = strings.TrimSuffix(options[0].BaseUrl, "/")
+		if options[0].Now != nil {
+			s.now = options[0].Now
+		}
+	}
+	return s
+}
+
+// CreateSignedUrl is like Client.CreateSignedUrl but signs the URL locally.
+// The object is not looked up, so the URL of a missing object is only
+// rejected when it is used.
+// bucketId string The bucket id
+// filePath path The file path, including the file name. Should be of the format `folder/subfolder/filename.png`
+// expiresIn int The number of seconds before the signed URL expires
+// urlOptions UrlOptions The transformation and download options
+func (s *LocalSigner) CreateSignedUrl(bucketId string, filePath string, expiresIn int, urlOptions ...UrlOptions) (SignedUrlResponse, error) {
+	var options UrlOptions
+	if len(urlOptions) > 0 {
+		options = urlOptions[0]
+	}
+	if err := options.validate(); err != nil {
+		return SignedUrlResponse{}, err
+	}
+	if expiresIn < 1 {
+		return SignedUrlResponse{}, errors.New("storage: expiresIn must be >= 1")
+	}
+
+	now := s.now().Unix()
+	objectPath, claim := signedObjectPath(bucketId, filePath)
+	claims := SignedUrlClaims{
+		URL:       claim,
+		IssuedAt:  now,
+		ExpiresAt: now + int64(expiresIn),
+	}
+	route := "/object/sign/"
+	if options.Transform != nil {
+		claims.Transformations = transformationClaim(*options.Transform)
+		if claims.Transformations != "" {
+			route = "/render/image/sign/"
+		}
+	}
+	token, err := s.Sign(claims)
+	if err != nil {
+		return SignedUrlResponse{}, err
+	}
+
+	signedURL := s.baseUrl + route + objectPath + "?token=" + token
+	if options.Download || options.DownloadFilename != "" {
+		signedURL = buildUrlWithOption(signedURL, UrlOptions{Download: options.Download, DownloadFilename: options.DownloadFilename})
+	}
+	return SignedUrlResponse{SignedURL: signedURL}, nil
+}
+
+// Sign returns the token of claims.
+func (s *LocalSigner) Sign(claims SignedUrlClaims) (string, error) {
+	// Encoded like JSON.stringify, which does not escape HTML characters.
+	var payload bytes.Buffer
+	encoder := json.NewEncoder(&payload)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(claims); err != nil {
+		return "", err
+	}
+	unsigned := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(bytes.TrimSuffix(payload.Bytes(), []byte("\n")))
+	return unsigned + "." + s.signature(unsigned), nil
+}
+
+// Verify checks the signature and expiry of a token and returns its claims.
+// The error is ErrInvalidToken or ErrTokenExpired; the claims of an expired
+// token are returned along with ErrTokenExpired.
+func (s *LocalSigner) Verify(token string) (SignedUrlClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return SignedUrlClaims{}, ErrInvalidToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeJWTPart(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return SignedUrlClaims{}, ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(s.signature(parts[0]+"."+parts[1]))) {
+		return SignedUrlClaims{}, ErrInvalidToken
+	}
+
+	var claims SignedUrlClaims
+	if err := decodeJWTPart(parts[1], &claims); err != nil || claims.URL == "" || claims.ExpiresAt == 0 {
+		return SignedUrlClaims{}, ErrInvalidToken
+	}
+	if s.now().Unix() >= claims.ExpiresAt {
+		return claims, ErrTokenExpired
+	}
+	return claims, nil
+}
+
+func (s *LocalSigner) signature(unsigned string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// transformationClaim formats a transformation like the server does in the
+// tokens of signed URLs. The server keeps the resize mode as given, and writes
+// `resize:undefined` when a size is set without one.
+func transformationClaim(t TransformOptions) string {
+	var segments []string
+	if t.Height > 0 {
+		segments = append(segments, "height:"+strconv.Itoa(t.Height))
+	}
+	if t.Width > 0 {
+		segments = append(segments, "width:"+strconv.Itoa(t.Width))
+	}
+	if t.Width > 0 || t.Height > 0 {
+		resize := string(t.Resize)
+		if resize == "" {
+			resize = "undefined"
+		}
+		segments = append(segments, "resize:"+resize)
+	}
+	if t.Quality > 0 {
+		segments = append(segments, "quality:"+strconv.Itoa(t.Quality))
+	}
+	if t.Format != "" && t.Format != FormatOrigin {
+		segments = append(segments, "format:"+string(t.Format))
+	}
+	return strings.Join(segments, ",")
+}
+
+// signedObjectPath returns the path of an object in a signed URL, escaped like
+// the path of a request to sign it, and the url claim the server derives from
+// that path with decodeURI.
+func signedObjectPath(bucketId string, filePath string) (urlPath string, claim string) {
+	urlPath = (&url.URL{Path: bucketId + "/" + filePath}).EscapedPath()
+	return urlPath, decodeURI(urlPath)
+}
+
+// decodeURI decodes the escapes of path like JavaScript's decodeURI, which
+// keeps the escapes of reserved characters.
+func decodeURI(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '%' && i+2 < len(path) {
+			if c, err := strconv.ParseUint(path[i+1:i+3], 16, 8); err == nil && !strings.ContainsRune(";/?:@&=+$,#", rune(c)) {
+				b.WriteByte(byte(c))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(path[i])
+	}
+	return b.String()
+}
@@ -1,12 +1,19 @@
 package storage_go
 
 import (
-	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"regexp"
 	"strconv"
+	"strings"
+	"sync"
 )
 
 const (
@@ -17,39 +24,42 @@ const (
 	defaultFileUpsert       = false
 	defaultSortColumn       = "name"
 	defaultSortOrder        = "asc"
+
+	defaultSignedUrlsBatchSize = 500
 )
 
+// UploadOrUpdateFile uploads data to the given path, replacing the existing file when update is true.
 func (c *Client) UploadOrUpdateFile(
 	bucketId string,
 	relativePath string,
 	data io.Reader,
 	update bool,
 	options ...FileOptions,
+) (FileUploadResponse, error) {
+	return c.UploadOrUpdateFileWithContext(context.Background(), bucketId, relativePath, data, update, options...)
+}
+
+// UploadOrUpdateFileWithContext is like UploadOrUpdateFile but carries ctx on the outgoing request.
+func (c *Client) UploadOrUpdateFileWithContext(
+	ctx context.Context,
+	bucketId string,
+	relativePath string,
+	data io.Reader,
+	update bool,
+	options ...FileOptions,
 ) (FileUploadResponse, error) {
 	path := removeEmptyFolderName(bucketId + "/" + relativePath)
 	uploadURL := c.clientTransport.baseUrl.String() + "/object/" + path
 
-	// Check on file options
-	if len(options) > 0 {
-		if options[0].CacheControl != nil {
-			c.clientTransport.header.Set("cache-control", *options[0].CacheControl)
-		}
-		if options[0].ContentType != nil {
-			c.clientTransport.header.Set("content-type", *options[0].ContentType)
-		}
-		if options[0].Upsert != nil {
-			c.clientTransport.header.Set("x-upsert", strconv.FormatBool(*options[0].Upsert))
-		}
-	}
 	method := http.MethodPost
 	if update {
 		method = http.MethodPut
 	}
-	bodyData := bufio.NewReader(data)
-	req, err := http.NewRequest(method, uploadURL, bodyData)
+	req, err := newUploadRequest(withIdempotent(ctx), method, uploadURL, data)
 	if err != nil {
 		return FileUploadResponse{}, err
 	}
+	setFileOptionHeaders(req.Header, options...)
 
 	var response FileUploadResponse
 	_, err = c.Do(req, &response)
@@ -65,7 +75,12 @@ func (c *Client) UploadOrUpdateFile(
 // relativePath path The file path, including the file name. Should be of the format `folder/subfolder/filename.png`
 // data io.Reader The file data
 func (c *Client) UpdateFile(bucketId string, relativePath string, data io.Reader, fileOptions ...FileOptions) (FileUploadResponse, error) {
-	return c.UploadOrUpdateFile(bucketId, relativePath, data, true, fileOptions...)
+	return c.UpdateFileWithContext(context.Background(), bucketId, relativePath, data, fileOptions...)
+}
+
+// UpdateFileWithContext is like UpdateFile but carries ctx on the outgoing request.
+func (c *Client) UpdateFileWithContext(ctx context.Context, bucketId string, relativePath string, data io.Reader, fileOptions ...FileOptions) (FileUploadResponse, error) {
+	return c.UploadOrUpdateFileWithContext(ctx, bucketId, relativePath, data, true, fileOptions...)
 }
 
 // UploadFile will upload file to an existing bucket at the specified path.
@@ -73,46 +88,129 @@ func (c *Client) UpdateFile(bucketId string, relativePath string, data io.Reader
 // relativePath path The file path, including the file name. Should be of the format `folder/subfolder/filename.png`
 // data io.Reader The file data
 func (c *Client) UploadFile(bucketId string, relativePath string, data io.Reader, fileOptions ...FileOptions) (FileUploadResponse, error) {
-	return c.UploadOrUpdateFile(bucketId, relativePath, data, false, fileOptions...)
+	return c.UploadFileWithContext(context.Background(), bucketId, relativePath, data, fileOptions...)
+}
+
+// UploadFileWithContext is like UploadFile but carries ctx on the outgoing request.
+func (c *Client) UploadFileWithContext(ctx context.Context, bucketId string, relativePath string, data io.Reader, fileOptions ...FileOptions) (FileUploadResponse, error) {
+	return c.UploadOrUpdateFileWithContext(ctx, bucketId, relativePath, data, false, fileOptions...)
+}
+
+// MoveFile will move an existing file to new path in the same bucket, or in
+// the bucket set by CopyOptions.DestinationBucket.
+// bucketId string The bucket id
+// sourceKey path The file path, including the file name. Should be of the format `folder/subfolder/filename.png`
+// destinationKey path The file path, including the file name. Should be of the format `folder/subfolder/new-filename.png`
+// options CopyOptions The destination bucket and upsert options
+func (c *Client) MoveFile(bucketId string, sourceKey string, destinationKey string, options ...CopyOptions) (FileUploadResponse, error) {
+	return c.MoveFileWithContext(context.Background(), bucketId, sourceKey, destinationKey, options...)
+}
+
+// MoveFileWithContext is like MoveFile but carries ctx on the outgoing request.
+func (c *Client) MoveFileWithContext(ctx context.Context, bucketId string, sourceKey string, destinationKey string, options ...CopyOptions) (FileUploadResponse, error) {
+	return c.moveOrCopyFile(ctx, "move", bucketId, sourceKey, destinationKey, options...)
 }
 
-// MoveFile will move an existing file to new path in the same bucket.
+// CopyFile will copy an existing file to a new path in the same bucket, or in
+// the bucket set by CopyOptions.DestinationBucket. The Key of the response is
+// the key of the copy.
 // bucketId string The bucket id
 // sourceKey path The file path, including the file name. Should be of the format `folder/subfolder/filename.png`
 // destinationKey path The file path, including the file name. Should be of the format `folder/subfolder/new-filename.png`
-func (c *Client) MoveFile(bucketId string, sourceKey string, destinationKey string) (FileUploadResponse, error) {
+// options CopyOptions The destination bucket, upsert and metadata options
+func (c *Client) CopyFile(bucketId string, sourceKey string, destinationKey string, options ...CopyOptions) (FileUploadResponse, error) {
+	return c.CopyFileWithContext(context.Background(), bucketId, sourceKey, destinationKey, options...)
+}
+
+// CopyFileWithContext is like CopyFile but carries ctx on the outgoing request.
+func (c *Client) CopyFileWithContext(ctx context.Context, bucketId string, sourceKey string, destinationKey string, options ...CopyOptions) (FileUploadResponse, error) {
+	return c.moveOrCopyFile(ctx, "copy", bucketId, sourceKey, destinationKey, options...)
+}
+
+// moveOrCopyFile sends a move or copy request. The Key of the response is set
+// to the destination when the server does not report it.
+func (c *Client) moveOrCopyFile(ctx context.Context, operation string, bucketId string, sourceKey string, destinationKey string, options ...CopyOptions) (FileUploadResponse, error) {
 	jsonBody := map[string]interface{}{
 		"bucketId":       bucketId,
 		"sourceKey":      sourceKey,
 		"destinationKey": destinationKey,
 	}
 
-	moveURL := c.clientTransport.baseUrl.String() + "/object/move"
-	req, err := c.NewRequest(http.MethodPost, moveURL, &jsonBody)
+	destinationBucket := bucketId
+	upsert := false
+	if len(options) > 0 {
+		option := options[0]
+		if option.DestinationBucket != "" {
+			destinationBucket = option.DestinationBucket
+			jsonBody["destinationBucket"] = option.DestinationBucket
+		}
+		upsert = option.Upsert
+		if operation == "copy" && (option.CacheControl != nil || option.ContentType != nil) {
+			metadata := map[string]string{}
+			if option.CacheControl != nil {
+				metadata["cacheControl"] = *option.CacheControl
+			}
+			if option.ContentType != nil {
+				metadata["mimetype"] = *option.ContentType
+			}
+			jsonBody["copyMetadata"] = false
+			jsonBody["metadata"] = metadata
+		}
+	}
+
+	requestURL := c.clientTransport.baseUrl.String() + "/object/" + operation
+	req, err := c.NewRequestWithContext(ctx, http.MethodPost, requestURL, &jsonBody)
 	if err != nil {
 		return FileUploadResponse{}, err
 	}
+	req.Header.Set("x-upsert", strconv.FormatBool(upsert))
+	if len(options) > 0 {
+		if metadata, ok := encodeUserMetadata(options[0].Metadata, options[0].MetadataJSON); ok {
+			req.Header.Set("x-metadata", metadata)
+		}
+	}
 
 	var response FileUploadResponse
 	_, err = c.Do(req, &response)
 	if err != nil {
 		return FileUploadResponse{}, err
 	}
+	if response.Key == "" {
+		response.Key = destinationBucket + "/" + destinationKey
+	}
 
 	return response, err
 }
 
 // CreateSignedUrl create a signed URL. Use a signed URL to share a file for a fixed amount of time.
+// With a Transform, the URL serves the transformed image from `render/image/sign`.
 // bucketId string The bucket id
 // filePath path The file path, including the file name. Should be of the format `folder/subfolder/filename.png`
 // expiresIn int The number of seconds before the signed URL expires. Defaults to 60 seconds.
-func (c *Client) CreateSignedUrl(bucketId string, filePath string, expiresIn int) (SignedUrlResponse, error) {
+// urlOptions UrlOptions The transformation and download options
+func (c *Client) CreateSignedUrl(bucketId string, filePath string, expiresIn int, urlOptions ...UrlOptions) (SignedUrlResponse, error) {
+	return c.CreateSignedUrlWithContext(context.Background(), bucketId, filePath, expiresIn, urlOptions...)
+}
+
+// CreateSignedUrlWithContext is like CreateSignedUrl but carries ctx on the outgoing request.
+func (c *Client) CreateSignedUrlWithContext(ctx context.Context, bucketId string, filePath string, expiresIn int, urlOptions ...UrlOptions) (SignedUrlResponse, error) {
+	var options UrlOptions
+	if len(urlOptions) > 0 {
+		options = urlOptions[0]
+	}
+	if err := options.validate(); err != nil {
+		return SignedUrlResponse{}, err
+	}
+
 	signedURL := c.clientTransport.baseUrl.String() + "/object/sign/" + bucketId + "/" + filePath
 	jsonBody := map[string]interface{}{
 		"expiresIn": expiresIn,
 	}
+	if options.Transform != nil {
+		jsonBody["transform"] = options.Transform
+	}
 
-	req, err := c.NewRequest(http.MethodPost, signedURL, &jsonBody)
+	req, err := c.NewRequestWithContext(withIdempotent(ctx), http.MethodPost, signedURL, &jsonBody)
 	if err != nil {
 		return SignedUrlResponse{}, err
 	}
@@ -123,19 +221,126 @@ func (c *Client) CreateSignedUrl(bucketId string, filePath string, expiresIn int
 		return SignedUrlResponse{}, err
 	}
 
-	response.SignedURL = c.clientTransport.baseUrl.String() + response.SignedURL
+	response.SignedURL = c.signedDownloadUrl(response.SignedURL, options)
 
 	return response, nil
 }
 
+// signedDownloadUrl returns the absolute URL of a signed path returned by the
+// server, with the download option of options. The transformation is part
+// of the signature and is not repeated in the query.
+func (c *Client) signedDownloadUrl(signedPath string, options UrlOptions) string {
+	signedURL := c.clientTransport.baseUrl.String() + signedPath
+	if !options.Download && options.DownloadFilename == "" {
+		return signedURL
+	}
+	return buildUrlWithOption(signedURL, UrlOptions{Download: options.Download, DownloadFilename: options.DownloadFilename})
+}
+
+// CreateSignedUrls create signed URLs for many files of a bucket. The paths are
+// signed in batches, so a long list costs a few requests instead of one per
+// path. The results are in the order of filePaths.
+// bucketId string The bucket id
+// filePaths []string The file paths, including the file names. Should be of the format `folder/subfolder/filename.png`
+// expiresIn int The number of seconds before the signed URLs expire.
+// options SignedUrlsOptions The batch size, transformation and download options
+func (c *Client) CreateSignedUrls(bucketId string, filePaths []string, expiresIn int, options ...SignedUrlsOptions) ([]SignedUrlResult, error) {
+	return c.CreateSignedUrlsWithContext(context.Background(), bucketId, filePaths, expiresIn, options...)
+}
+
+// CreateSignedUrlsWithContext is like CreateSignedUrls but carries ctx on the outgoing requests.
+func (c *Client) CreateSignedUrlsWithContext(ctx context.Context, bucketId string, filePaths []string, expiresIn int, options ...SignedUrlsOptions) ([]SignedUrlResult, error) {
+	var option SignedUrlsOptions
+	if len(options) > 0 {
+		option = options[0]
+	}
+	if option.Transform != nil {
+		if err := option.Transform.Validate(); err != nil {
+			return nil, err
+		}
+		return c.createTransformedSignedUrls(ctx, bucketId, filePaths, expiresIn, option.UrlOptions)
+	}
+	batchSize := defaultSignedUrlsBatchSize
+	if option.BatchSize > 0 {
+		batchSize = option.BatchSize
+	}
+
+	signURL := c.clientTransport.baseUrl.String() + "/object/sign/" + bucketId
+	results := make([]SignedUrlResult, 0, len(filePaths))
+	for start := 0; start < len(filePaths); start += batchSize {
+		end := start + batchSize
+		if end > len(filePaths) {
+			end = len(filePaths)
+		}
+		batch := filePaths[start:end]
+		jsonBody := map[string]interface{}{
+			"expiresIn": expiresIn,
+			"paths":     batch,
+		}
+
+		req, err := c.NewRequestWithContext(withIdempotent(ctx), http.MethodPost, signURL, &jsonBody)
+		if err != nil {
+			return nil, err
+		}
+
+		var response []SignedUrlResult
+		_, err = c.Do(req, &response)
+		if err != nil {
+			return nil, err
+		}
+		if len(response) != len(batch) {
+			return nil, fmt.Errorf("storage: signed %d of %d paths", len(response), len(batch))
+		}
+
+		for i, result := range response {
+			// Failed paths may come back without their path.
+			result.Path = batch[i]
+			if result.SignedURL != "" {
+				result.SignedURL = c.signedDownloadUrl(result.SignedURL, option.UrlOptions)
+			}
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+// createTransformedSignedUrls signs the paths one at a time, as the server
+// does not sign transformations in batches. A path the server refuses to sign
+// is reported in its result.
+func (c *Client) createTransformedSignedUrls(ctx context.Context, bucketId string, filePaths []string, expiresIn int, options UrlOptions) ([]SignedUrlResult, error) {
+	results := make([]SignedUrlResult, 0, len(filePaths))
+	for _, filePath := range filePaths {
+		result := SignedUrlResult{Path: filePath}
+		response, err := c.CreateSignedUrlWithContext(ctx, bucketId, filePath, expiresIn, options)
+		var storageErr *StorageError
+		switch {
+		case errors.As(err, &storageErr):
+			result.Error = storageErr.Message
+		case err != nil:
+			return nil, err
+		default:
+			result.SignedURL = response.SignedURL
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
 // CreateSignedUploadUrl create a signed URL for uploading a file. Use a signed URL to upload a file directly to a bucket.
 // bucketId string The bucket id
 // filePath path The file path, including the file name. Should be of the format `folder/subfolder/filename.png`
 func (c *Client) CreateSignedUploadUrl(bucketId string, filePath string) (SignedUploadUrlResponse, error) {
+	return c.CreateSignedUploadUrlWithContext(context.Background(), bucketId, filePath)
+}
+
+// CreateSignedUploadUrlWithContext is like CreateSignedUploadUrl but carries ctx on the outgoing request.
+func (c *Client) CreateSignedUploadUrlWithContext(ctx context.Context, bucketId string, filePath string) (SignedUploadUrlResponse, error) {
 	signUploadURL := c.clientTransport.baseUrl.String() + "/object/upload/sign/" + bucketId + "/" + filePath
 	emptyBody := struct{}{}
 
-	req, err := c.NewRequest(http.MethodPost, signUploadURL, &emptyBody)
+	req, err := c.NewRequestWithContext(withIdempotent(ctx), http.MethodPost, signUploadURL, &emptyBody)
 	if err != nil {
 		return SignedUploadUrlResponse{}, err
 	}
@@ -152,19 +357,21 @@ func (c *Client) CreateSignedUploadUrl(bucketId string, filePath string) (Signed
 // UploadToSignedUrl upload a file to a signed URL.
 // filePath string The file path, including the file name. Should be of the format `folder/subfolder/filename.png`
 // fileBody io.Reader The file data
-func (c *Client) UploadToSignedUrl(filePath string, fileBody io.Reader) (*UploadToSignedUrlResponse, error) {
-	c.clientTransport.header.Set("cache-control", defaultFileCacheControl)
-	c.clientTransport.header.Set("content-type", defaultFileContentType)
-	c.clientTransport.header.Set("x-upsert", strconv.FormatBool(defaultFileUpsert))
+// fileOptions FileOptions The content type, cache control and user metadata of the file
+func (c *Client) UploadToSignedUrl(filePath string, fileBody io.Reader, fileOptions ...FileOptions) (*UploadToSignedUrlResponse, error) {
+	return c.UploadToSignedUrlWithContext(context.Background(), filePath, fileBody, fileOptions...)
+}
 
-	bodyRequest := bufio.NewReader(fileBody)
+// UploadToSignedUrlWithContext is like UploadToSignedUrl but carries ctx on the outgoing request.
+func (c *Client) UploadToSignedUrlWithContext(ctx context.Context, filePath string, fileBody io.Reader, fileOptions ...FileOptions) (*UploadToSignedUrlResponse, error) {
 	path := removeEmptyFolderName(filePath)
 	uploadToSignedURL := c.clientTransport.baseUrl.String() + path
 
-	req, err := http.NewRequest(http.MethodPut, uploadToSignedURL, bodyRequest)
+	req, err := newUploadRequest(ctx, http.MethodPut, uploadToSignedURL, fileBody)
 	if err != nil {
 		return nil, err
 	}
+	setFileOptionHeaders(req.Header, fileOptions...)
 
 	var response UploadToSignedUrlResponse
 	_, err = c.Do(req, &response)
@@ -178,13 +385,16 @@ func (c *Client) UploadToSignedUrl(filePath string, fileBody io.Reader) (*Upload
 // GetPublicUrl use to to get the URL for an asset in a public bucket. If you do not want to use this function, you can construct the public URL by concatenating the bucket URL with the path to the asset.
 // bucketId string The bucket id
 // filePath path The file path, including the file name. Should be of the format `folder/subfolder/filename.png`
-// urlOptions UrlOptions The URL options
+// urlOptions UrlOptions The URL options. An invalid transformation, see TransformOptions.Validate, is left out and the URL is the one of the original object; use GetPublicUrlWithTransform to get an error instead.
 func (c *Client) GetPublicUrl(bucketId string, filePath string, urlOptions ...UrlOptions) SignedUrlResponse {
 	var response SignedUrlResponse
 	renderPath := "object"
 	var options UrlOptions
 	if len(urlOptions) > 0 {
 		options = urlOptions[0]
+		if options.Transform != nil && options.Transform.Validate() != nil {
+			options.Transform = nil
+		}
 		if options.Transform != nil {
 			renderPath = "render/image"
 		}
@@ -195,16 +405,40 @@ func (c *Client) GetPublicUrl(bucketId string, filePath string, urlOptions ...Ur
 	return response
 }
 
+// GetPublicUrlWithTransform is like GetPublicUrl for a transformed image, but
+// returns an error wrapping ErrInvalidTransform if the transformation is not
+// valid, see TransformOptions.Validate.
+// bucketId string The bucket id
+// filePath path The file path, including the file name. Should be of the format `folder/subfolder/filename.png`
+// transform TransformOptions The transformation of the image
+// urlOptions UrlOptions The download options. Their transformation is replaced by transform.
+func (c *Client) GetPublicUrlWithTransform(bucketId string, filePath string, transform TransformOptions, urlOptions ...UrlOptions) (string, error) {
+	if err := transform.Validate(); err != nil {
+		return "", err
+	}
+	var options UrlOptions
+	if len(urlOptions) > 0 {
+		options = urlOptions[0]
+	}
+	options.Transform = &transform
+	return c.GetPublicUrl(bucketId, filePath, options).SignedURL, nil
+}
+
 // RemoveFile remove a file from an existing bucket.
 // bucketId string The bucket id.
 // paths []string The file paths, including the file name. Should be of the format `folder/subfolder/filename.png`
 func (c *Client) RemoveFile(bucketId string, paths []string) ([]FileUploadResponse, error) {
+	return c.RemoveFileWithContext(context.Background(), bucketId, paths)
+}
+
+// RemoveFileWithContext is like RemoveFile but carries ctx on the outgoing request.
+func (c *Client) RemoveFileWithContext(ctx context.Context, bucketId string, paths []string) ([]FileUploadResponse, error) {
 	removeURL := c.clientTransport.baseUrl.String() + "/object/" + bucketId
 	jsonBody := map[string]interface{}{
 		"prefixes": paths,
 	}
 
-	req, err := c.NewRequest(http.MethodDelete, removeURL, &jsonBody)
+	req, err := c.NewRequestWithContext(ctx, http.MethodDelete, removeURL, &jsonBody)
 	if err != nil {
 		return []FileUploadResponse{}, err
 	}
@@ -223,6 +457,11 @@ func (c *Client) RemoveFile(bucketId string, paths []string) ([]FileUploadRespon
 // queryPath string The file path, including the file name. Should be of the format `folder/subfolder/filename.png`
 // options FileSearchOptions The file search options
 func (c *Client) ListFiles(bucketId string, queryPath string, options FileSearchOptions) ([]FileObject, error) {
+	return c.ListFilesWithContext(context.Background(), bucketId, queryPath, options)
+}
+
+// ListFilesWithContext is like ListFiles but carries ctx on the outgoing request.
+func (c *Client) ListFilesWithContext(ctx context.Context, bucketId string, queryPath string, options FileSearchOptions) ([]FileObject, error) {
 	if options.Offset == 0 {
 		options.Offset = defaultOffset
 	}
@@ -250,7 +489,7 @@ func (c *Client) ListFiles(bucketId string, queryPath string, options FileSearch
 	}
 
 	listFileURL := c.clientTransport.baseUrl.String() + "/object/list/" + bucketId
-	req, err := c.NewRequest(http.MethodPost, listFileURL, &body)
+	req, err := c.NewRequestWithContext(withIdempotent(ctx), http.MethodPost, listFileURL, &body)
 	if err != nil {
 		return []FileObject{}, err
 	}
@@ -264,11 +503,92 @@ func (c *Client) ListFiles(bucketId string, queryPath string, options FileSearch
 	return response, nil
 }
 
+// ListObjectsV2 lists a page of objects with the list-v2 endpoint, which pages
+// with a cursor instead of an offset and is much faster on large buckets.
+// bucketId string The bucket id
+// request ListObjectsV2Request The prefix, cursor and page size
+func (c *Client) ListObjectsV2(bucketId string, request ListObjectsV2Request) (ListObjectsV2Response, error) {
+	return c.ListObjectsV2WithContext(context.Background(), bucketId, request)
+}
+
+// ListObjectsV2WithContext is like ListObjectsV2 but carries ctx on the outgoing request.
+func (c *Client) ListObjectsV2WithContext(ctx context.Context, bucketId string, request ListObjectsV2Request) (ListObjectsV2Response, error) {
+	listURL := c.clientTransport.baseUrl.String() + "/object/list-v2/" + bucketId
+	req, err := c.NewRequestWithContext(withIdempotent(ctx), http.MethodPost, listURL, &request)
+	if err != nil {
+		return ListObjectsV2Response{}, err
+	}
+
+	var response ListObjectsV2Response
+	_, err = c.Do(req, &response)
+	if err != nil {
+		return ListObjectsV2Response{}, err
+	}
+
+	return response, nil
+}
+
+// ListObjectsV2Pages calls fn with each page of a list-v2 request, following
+// the cursor until the last page or until fn returns false.
+// bucketId string The bucket id
+// request ListObjectsV2Request The prefix, page size and the cursor to start from
+// fn func The function called with each page
+func (c *Client) ListObjectsV2Pages(bucketId string, request ListObjectsV2Request, fn func(page ListObjectsV2Response) bool) error {
+	return c.ListObjectsV2PagesWithContext(context.Background(), bucketId, request, fn)
+}
+
+// ListObjectsV2PagesWithContext is like ListObjectsV2Pages but carries ctx on the outgoing requests.
+func (c *Client) ListObjectsV2PagesWithContext(ctx context.Context, bucketId string, request ListObjectsV2Request, fn func(page ListObjectsV2Response) bool) error {
+	for {
+		page, err := c.ListObjectsV2WithContext(ctx, bucketId, request)
+		if err != nil {
+			return err
+		}
+		if !fn(page) || !page.HasNext {
+			return nil
+		}
+		if page.NextCursor == "" || page.NextCursor == request.Cursor {
+			return errors.New("storage: list objects v2: server reported more pages without a new cursor")
+		}
+		request.Cursor = page.NextCursor
+	}
+}
+
 // DownloadFile download a file from an existing bucket.
 // bucketId string The bucket id.
 // filePath string The file path, including the file name. Should be of the format `folder/subfolder/filename.png`
 // urlOptions UrlOptions The URL options
 func (c *Client) DownloadFile(bucketId string, filePath string, urlOptions ...UrlOptions) ([]byte, error) {
+	return c.DownloadFileWithContext(context.Background(), bucketId, filePath, urlOptions...)
+}
+
+// DownloadFileWithContext is like DownloadFile but carries ctx on the outgoing request.
+func (c *Client) DownloadFileWithContext(ctx context.Context, bucketId string, filePath string, urlOptions ...UrlOptions) ([]byte, error) {
+	body, _, err := c.DownloadFileStreamWithContext(ctx, bucketId, filePath, urlOptions...)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+
+	return data, nil
+}
+
+// DownloadFileStream download a file from an existing bucket without buffering it in memory.
+// The caller must close the returned body.
+// bucketId string The bucket id.
+// filePath string The file path, including the file name. Should be of the format `folder/subfolder/filename.png`
+// urlOptions UrlOptions The URL options
+func (c *Client) DownloadFileStream(bucketId string, filePath string, urlOptions ...UrlOptions) (io.ReadCloser, ObjectInfo, error) {
+	return c.DownloadFileStreamWithContext(context.Background(), bucketId, filePath, urlOptions...)
+}
+
+// DownloadFileStreamWithContext is like DownloadFileStream but carries ctx on the outgoing request.
+func (c *Client) DownloadFileStreamWithContext(ctx context.Context, bucketId string, filePath string, urlOptions ...UrlOptions) (io.ReadCloser, ObjectInfo, error) {
 	var options UrlOptions
 	renderPath := "object"
 	if len(urlOptions) > 0 {
@@ -277,20 +597,270 @@ func (c *Client) DownloadFile(bucketId string, filePath string, urlOptions ...Ur
 			renderPath = "render/image/authenticated"
 		}
 	}
+	if err := options.validate(); err != nil {
+		return nil, ObjectInfo{}, err
+	}
 	urlStr := c.clientTransport.baseUrl.String() + "/" + renderPath + "/" + bucketId + "/" + filePath
-	req, err := c.NewRequest(http.MethodGet, buildUrlWithOption(urlStr, options), nil)
+	req, err := c.NewRequestWithContext(ctx, http.MethodGet, buildUrlWithOption(urlStr, options), nil)
 	if err != nil {
-		return nil, err
+		return nil, ObjectInfo{}, err
+	}
+
+	res, err := c.Do(req, nil)
+	if err != nil {
+		if res != nil {
+			res.Body.Close()
+		}
+		return nil, ObjectInfo{}, err
+	}
+
+	return res.Body, objectInfoFromResponse(res), nil
+}
+
+// headObject fetches the headers of an object without downloading it.
+func (c *Client) headObject(ctx context.Context, bucketId string, filePath string) (ObjectInfo, error) {
+	headURL := c.clientTransport.baseUrl.String() + "/object/" + bucketId + "/" + filePath
+	req, err := c.NewRequestWithContext(ctx, http.MethodHead, headURL, nil)
+	if err != nil {
+		return ObjectInfo{}, err
 	}
 
 	res, err := c.Do(req, nil)
+	if res != nil {
+		defer res.Body.Close()
+	}
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	return objectInfoFromResponse(res), nil
+}
+
+// GetFileInfo returns the metadata of an object without downloading it, from
+// the info route. Servers without that route are asked with a HEAD request,
+// which only reports the metadata of the headers of a download.
+// bucketId string The bucket id
+// filePath path The file path, including the file name. Should be of the format `folder/subfolder/filename.png`
+func (c *Client) GetFileInfo(bucketId string, filePath string) (ObjectInfo, error) {
+	return c.GetFileInfoWithContext(context.Background(), bucketId, filePath)
+}
+
+// GetFileInfoWithContext is like GetFileInfo but carries ctx on the outgoing requests.
+func (c *Client) GetFileInfoWithContext(ctx context.Context, bucketId string, filePath string) (ObjectInfo, error) {
+	infoURL := c.clientTransport.baseUrl.String() + "/object/info/" + bucketId + "/" + filePath
+	req, err := c.NewRequestWithContext(ctx, http.MethodGet, infoURL, nil)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	var response objectInfoResponse
+	_, err = c.Do(req, &response)
+	if isMissingRoute(err) {
+		info, err := c.headObject(ctx, bucketId, filePath)
+		if err != nil {
+			return ObjectInfo{}, err
+		}
+		info.Name = filePath
+		info.BucketId = bucketId
+		return info, nil
+	}
 	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	info := ObjectInfo{
+		ContentType:   response.ContentType,
+		ContentLength: response.Size,
+		ETag:          response.ETag,
+		CacheControl:  response.CacheControl,
+		Id:            response.Id,
+		Name:          response.Name,
+		BucketId:      response.BucketId,
+		Version:       response.Version,
+		Metadata:      response.Metadata,
+	}
+	info.LastModified = parseTime(&response.LastModified)
+	info.CreatedAt = parseTime(&response.CreatedAt)
+
+	return info, nil
+}
+
+// isMissingRoute reports whether err is the answer of a server that does not
+// have the requested route, rather than a missing object.
+func isMissingRoute(err error) bool {
+	var storageErr *StorageError
+	return errors.As(err, &storageErr) && storageErr.Status == http.StatusNotFound && strings.HasPrefix(storageErr.Message, "Route ")
+}
+
+// objectInfoResponse is the body of the info route.
+type objectInfoResponse struct {
+	Id           string                 `json:"id"`
+	Name         string                 `json:"name"`
+	Version      string                 `json:"version"`
+	BucketId     string                 `json:"bucket_id"`
+	Size         int64                  `json:"size"`
+	ContentType  string                 `json:"content_type"`
+	CacheControl string                 `json:"cache_control"`
+	ETag         string                 `json:"etag"`
+	Metadata     map[string]interface{} `json:"metadata"`
+	LastModified string                 `json:"last_modified"`
+	CreatedAt    string                 `json:"created_at"`
+}
+
+// Exists reports whether an object exists, with GetFileInfo. Only a missing
+// object or bucket is reported as false; any other error is returned.
+// bucketId string The bucket id
+// filePath path The file path, including the file name. Should be of the format `folder/subfolder/filename.png`
+func (c *Client) Exists(bucketId string, filePath string) (bool, error) {
+	return c.ExistsWithContext(context.Background(), bucketId, filePath)
+}
+
+// ExistsWithContext is like Exists but carries ctx on the outgoing requests.
+func (c *Client) ExistsWithContext(ctx context.Context, bucketId string, filePath string) (bool, error) {
+	_, err := c.GetFileInfoWithContext(ctx, bucketId, filePath)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, ErrNotFound):
+		return false, nil
+	}
+	return false, err
+}
+
+// objectInfoFromResponse reads the object metadata from the headers of a download response.
+func objectInfoFromResponse(res *http.Response) ObjectInfo {
+	lastModified, _ := http.ParseTime(res.Header.Get("Last-Modified"))
+	return ObjectInfo{
+		ContentType:   res.Header.Get("Content-Type"),
+		ContentLength: res.ContentLength,
+		ETag:          res.Header.Get("ETag"),
+		LastModified:  lastModified,
+		CacheControl:  res.Header.Get("Cache-Control"),
+	}
+}
+
+// newUploadRequest creates a request sending data as its body. The body can be
+// rewound for retries when data is held in memory or is an io.Seeker, and it
+// is never closed, so files passed by the caller stay open.
+func newUploadRequest(ctx context.Context, method string, url string, data io.Reader) (*http.Request, error) {
+	switch data.(type) {
+	case *bytes.Buffer, *bytes.Reader, *strings.Reader:
+		// http.NewRequest knows the length of these and how to rewind them.
+		return http.NewRequestWithContext(ctx, method, url, data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, io.NopCloser(data))
+	if err != nil {
+		return nil, err
+	}
+
+	seeker, ok := data.(io.Seeker)
+	if !ok {
+		return req, nil
+	}
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		// Not actually seekable, e.g. a pipe.
+		return req, nil
+	}
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := seeker.Seek(start, io.SeekStart); err != nil {
 		return nil, err
 	}
-	defer res.Body.Close()
 
-	body, err := io.ReadAll(res.Body)
-	return body, err
+	req.ContentLength = end - start
+	if req.ContentLength == 0 {
+		req.Body = http.NoBody
+		req.GetBody = func() (io.ReadCloser, error) { return http.NoBody, nil }
+		return req, nil
+	}
+
+	// net/http may still be writing the body of an attempt after Do returned,
+	// e.g. when the server answered before reading it, so every attempt reads
+	// its own section of data, or waits for the previous body to be closed
+	// before rewinding data.
+	if readerAt, ok := data.(io.ReaderAt); ok {
+		req.Body = io.NopCloser(io.NewSectionReader(readerAt, start, req.ContentLength))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(io.NewSectionReader(readerAt, start, req.ContentLength)), nil
+		}
+		return req, nil
+	}
+
+	body := newReleasedBody(data)
+	req.Body = body
+	req.GetBody = func() (io.ReadCloser, error) {
+		select {
+		case <-body.released:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+			return nil, err
+		}
+		body = newReleasedBody(data)
+		return body, nil
+	}
+
+	return req, nil
+}
+
+// releasedBody is the body of an upload attempt. It does not close the data
+// of the caller, but reports when net/http is done with it.
+type releasedBody struct {
+	io.Reader
+	once     sync.Once
+	released chan struct{}
+}
+
+func newReleasedBody(data io.Reader) *releasedBody {
+	return &releasedBody{Reader: data, released: make(chan struct{})}
+}
+
+func (b *releasedBody) Close() error {
+	b.once.Do(func() { close(b.released) })
+	return nil
+}
+
+// setFileOptionHeaders sets the upload headers for options on header, falling
+// back to the defaults for any option that is not provided. The headers are
+// set on the request itself so they never leak into other requests.
+func setFileOptionHeaders(header http.Header, options ...FileOptions) {
+	header.Set("cache-control", defaultFileCacheControl)
+	header.Set("content-type", defaultFileContentType)
+	header.Set("x-upsert", strconv.FormatBool(defaultFileUpsert))
+
+	if len(options) == 0 {
+		return
+	}
+	if options[0].CacheControl != nil {
+		header.Set("cache-control", *options[0].CacheControl)
+	}
+	if options[0].ContentType != nil {
+		header.Set("content-type", *options[0].ContentType)
+	}
+	if options[0].Upsert != nil {
+		header.Set("x-upsert", strconv.FormatBool(*options[0].Upsert))
+	}
+	if metadata, ok := encodeUserMetadata(options[0].Metadata, options[0].MetadataJSON); ok {
+		header.Set("x-metadata", metadata)
+	}
+}
+
+// encodeUserMetadata encodes user metadata as the base64 JSON of the
+// x-metadata header. raw takes precedence over metadata.
+func encodeUserMetadata(metadata map[string]string, raw json.RawMessage) (string, bool) {
+	data := []byte(raw)
+	if len(data) == 0 {
+		if metadata == nil {
+			return "", false
+		}
+		data, _ = json.Marshal(metadata)
+	}
+	return base64.StdEncoding.EncodeToString(data), true
 }
 
 // buildUrlWithOption will base on current url and option to build a new url
@@ -310,18 +880,21 @@ func buildUrlWithOption(urlStr string, options UrlOptions) string {
 		}
 		// Default: origin
 		if options.Transform.Format != "" {
-			signedURLQuery.Add("format", options.Transform.Format)
+			signedURLQuery.Add("format", string(options.Transform.Format))
 		}
 		// Default: 80
 		if options.Transform.Quality > 0 {
 			signedURLQuery.Add("quality", strconv.Itoa(options.Transform.Quality))
 		}
-		if options.Transform.Resize != "" && (options.Transform.Resize == "conver" || options.Transform.Resize == "contain" || options.Transform.Resize == "fill") {
-			signedURLQuery.Add("resize", options.Transform.Resize)
+		// Default: cover
+		if options.Transform.Resize != "" {
+			signedURLQuery.Add("resize", string(options.Transform.Resize))
 		}
 	}
 	// Default on server is false
-	if options.Download == true {
+	if options.DownloadFilename != "" {
+		signedURLQuery.Add("download", options.DownloadFilename)
+	} else if options.Download == true {
 		signedURLQuery.Add("download", strconv.FormatBool(options.Download))
 	}
 
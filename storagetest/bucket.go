@@ -0,0 +1,192 @@
+package storagetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// bucketJSON is the bucket representation of the Storage API.
+type bucketJSON struct {
+	Id               string   `json:"id"`
+	Name             string   `json:"name"`
+	Owner            string   `json:"owner"`
+	Public           bool     `json:"public"`
+	FileSizeLimit    *int64   `json:"file_size_limit"`
+	AllowedMimeTypes []string `json:"allowed_mime_types"`
+	CreatedAt        string   `json:"created_at"`
+	UpdatedAt        string   `json:"updated_at"`
+}
+
+func (b *bucket) toJSON() bucketJSON {
+	return bucketJSON{
+		Id:               b.id,
+		Name:             b.id,
+		Public:           b.public,
+		FileSizeLimit:    b.fileSizeLimit,
+		AllowedMimeTypes: b.allowedMimeTypes,
+		CreatedAt:        formatTime(b.createdAt),
+		UpdatedAt:        formatTime(b.updatedAt),
+	}
+}
+
+// bucketRequest is the body of the create and update bucket routes.
+type bucketRequest struct {
+	Id               string          `json:"id"`
+	Name             string          `json:"name"`
+	Public           *bool           `json:"public"`
+	FileSizeLimit    json.RawMessage `json:"file_size_limit"`
+	AllowedMimeTypes []string        `json:"allowed_mime_types"`
+}
+
+// apply updates b with the constraints of req.
+func (req bucketRequest) apply(b *bucket) error {
+	if req.Public != nil {
+		b.public = *req.Public
+	}
+	if len(req.FileSizeLimit) > 0 && string(req.FileSizeLimit) != "null" {
+		limit, err := parseFileSizeLimit(req.FileSizeLimit)
+		if err != nil {
+			return err
+		}
+		b.fileSizeLimit = &limit
+	}
+	if req.AllowedMimeTypes != nil {
+		b.allowedMimeTypes = req.AllowedMimeTypes
+	}
+	return nil
+}
+
+// parseFileSizeLimit accepts a number of bytes or a string such as "20MB".
+func parseFileSizeLimit(raw json.RawMessage) (int64, error) {
+	var limit int64
+	if err := json.Unmarshal(raw, &limit); err == nil {
+		return limit, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return 0, err
+	}
+	s = strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			multiplier = unit.size
+			break
+		}
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	return int64(value * float64(multiplier)), nil
+}
+
+func (s *Server) listBuckets(w http.ResponseWriter, r *http.Request) {
+	ids := make([]string, 0, len(s.buckets))
+	for id := range s.buckets {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	buckets := make([]bucketJSON, 0, len(ids))
+	for _, id := range ids {
+		buckets = append(buckets, s.buckets[id].toJSON())
+	}
+	writeJSON(w, http.StatusOK, buckets)
+}
+
+func (s *Server) createBucket(w http.ResponseWriter, r *http.Request) {
+	var req bucketRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Invalid request body")
+		return
+	}
+	id := req.Id
+	if id == "" {
+		id = req.Name
+	}
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "body must have required property 'name'")
+		return
+	}
+	if _, ok := s.buckets[id]; ok {
+		writeError(w, http.StatusConflict, "Duplicate", "The resource already exists")
+		return
+	}
+
+	now := s.now()
+	b := &bucket{
+		id:        id,
+		createdAt: now,
+		updatedAt: now,
+		objects:   map[string]*object{},
+	}
+	if err := req.apply(b); err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Invalid file_size_limit")
+		return
+	}
+	s.buckets[id] = b
+
+	writeJSON(w, http.StatusOK, map[string]string{"name": id})
+}
+
+func (s *Server) getBucket(w http.ResponseWriter, id string) {
+	b, ok := s.buckets[id]
+	if !ok {
+		bucketNotFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, b.toJSON())
+}
+
+func (s *Server) updateBucket(w http.ResponseWriter, r *http.Request, id string) {
+	b, ok := s.buckets[id]
+	if !ok {
+		bucketNotFound(w)
+		return
+	}
+	var req bucketRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Invalid request body")
+		return
+	}
+	if err := req.apply(b); err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Invalid file_size_limit")
+		return
+	}
+	b.updatedAt = s.now()
+
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Successfully updated"})
+}
+
+func (s *Server) emptyBucket(w http.ResponseWriter, id string) {
+	b, ok := s.buckets[id]
+	if !ok {
+		bucketNotFound(w)
+		return
+	}
+	b.objects = map[string]*object{}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Successfully emptied"})
+}
+
+func (s *Server) deleteBucket(w http.ResponseWriter, id string) {
+	b, ok := s.buckets[id]
+	if !ok {
+		bucketNotFound(w)
+		return
+	}
+	if len(b.objects) > 0 {
+		writeError(w, http.StatusConflict, "InvalidRequest", "The bucket you tried to delete is not empty")
+		return
+	}
+	delete(s.buckets, id)
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Successfully deleted"})
+}
@@ -0,0 +1,147 @@
+package storagetest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SetJWTSecret makes the server sign download URLs with JWTs like the real
+// server, with the JWT secret of a project, instead of opaque tokens. Signed
+// URLs whose token is signed with the secret are then accepted, such as those
+// of storage_go.LocalSigner.
+func (s *Server) SetJWTSecret(secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jwtSecret = []byte(secret)
+}
+
+// jwtClaims are the claims of the tokens of signed URLs, in the order the
+// server writes them.
+type jwtClaims struct {
+	URL             string `json:"url"`
+	Transformations string `json:"transformations,omitempty"`
+	IssuedAt        int64  `json:"iat"`
+	ExpiresAt       int64  `json:"exp"`
+}
+
+// transformRequest is the transform of a request to sign a URL.
+type transformRequest struct {
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+	Resize  string `json:"resize"`
+	Quality int    `json:"quality"`
+	Format  string `json:"format"`
+}
+
+// signJWT signs the URL of the object at escapedPath, `bucket/folder/file.png`
+// escaped as in a request path, and returns the signed URL relative to the
+// server. It mirrors signObjectUrl of the server: the url claim is escapedPath
+// through decodeURI, and the transformations are written by
+// ImageRenderer.applyTransformation(transform, true).
+func (s *Server) signJWT(escapedPath string, expiresIn time.Duration, transform *transformRequest) string {
+	now := s.now().Unix()
+	claims := jwtClaims{
+		URL:       decodeURI(escapedPath),
+		IssuedAt:  now,
+		ExpiresAt: now + int64(expiresIn/time.Second),
+	}
+	if transform != nil {
+		claims.Transformations = transformationsClaim(*transform)
+	}
+	// Encoded like JSON.stringify, which does not escape HTML characters.
+	var payload bytes.Buffer
+	encoder := json.NewEncoder(&payload)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(claims)
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + base64.RawURLEncoding.EncodeToString(bytes.TrimSuffix(payload.Bytes(), []byte("\n")))
+	token := unsigned + "." + s.jwtSignature(unsigned)
+
+	route := "/object/sign/"
+	if claims.Transformations != "" {
+		route = "/render/image/sign/"
+	}
+	return route + escapedPath + "?token=" + token
+}
+
+// validJWT reports whether the token of r is a JWT signed for the object of
+// the request path and writes the error response if it is not.
+func (s *Server) validJWT(w http.ResponseWriter, r *http.Request, transform bool) bool {
+	parts := strings.Split(r.URL.Query().Get("token"), ".")
+	var claims jwtClaims
+	if len(parts) != 3 || !hmac.Equal([]byte(parts[2]), []byte(s.jwtSignature(parts[0]+"."+parts[1]))) {
+		writeError(w, http.StatusBadRequest, "InvalidJWT", "invalid signature")
+		return false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || json.Unmarshal(payload, &claims) != nil {
+		writeError(w, http.StatusBadRequest, "InvalidJWT", "invalid signature")
+		return false
+	}
+	if s.now().Unix() >= claims.ExpiresAt {
+		writeError(w, http.StatusBadRequest, "InvalidJWT", "jwt expired")
+		return false
+	}
+	escapedPath := r.URL.EscapedPath()
+	escapedPath = escapedPath[strings.Index(escapedPath, "/sign/")+len("/sign/"):]
+	if claims.URL != decodeURI(escapedPath) || (claims.Transformations != "") != transform {
+		writeError(w, http.StatusBadRequest, "InvalidSignature", "The url do not match the signature")
+		return false
+	}
+	return true
+}
+
+func (s *Server) jwtSignature(unsigned string) string {
+	mac := hmac.New(sha256.New, s.jwtSecret)
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// transformationsClaim writes a transformation like
+// ImageRenderer.applyTransformation(transform, true) of the server.
+func transformationsClaim(t transformRequest) string {
+	var segments []string
+	if t.Height != 0 {
+		segments = append(segments, "height:"+strconv.Itoa(t.Height))
+	}
+	if t.Width != 0 {
+		segments = append(segments, "width:"+strconv.Itoa(t.Width))
+	}
+	if t.Width != 0 || t.Height != 0 {
+		resize := t.Resize
+		if resize == "" {
+			resize = "undefined"
+		}
+		segments = append(segments, "resize:"+resize)
+	}
+	if t.Quality != 0 {
+		segments = append(segments, "quality:"+strconv.Itoa(t.Quality))
+	}
+	if t.Format != "" && t.Format != "origin" {
+		segments = append(segments, "format:"+t.Format)
+	}
+	return strings.Join(segments, ",")
+}
+
+// decodeURI decodes escapes like JavaScript's decodeURI, which keeps the
+// escapes of reserved characters.
+func decodeURI(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '%' && i+2 < len(path) {
+			if c, err := strconv.ParseUint(path[i+1:i+3], 16, 8); err == nil && !strings.ContainsRune(";/?:@&=+$,#", rune(c)) {
+				b.WriteByte(byte(c))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(path[i])
+	}
+	return b.String()
+}
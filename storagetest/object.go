@@ -0,0 +1,735 @@
+package storagetest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultContentType  = "text/plain;charset=UTF-8"
+	defaultCacheControl = "no-cache"
+)
+
+// objectMetadata is the metadata of an object in list responses.
+type objectMetadata struct {
+	ETag           string `json:"eTag"`
+	Size           int    `json:"size"`
+	Mimetype       string `json:"mimetype"`
+	CacheControl   string `json:"cacheControl"`
+	LastModified   string `json:"lastModified"`
+	ContentLength  int    `json:"contentLength"`
+	HttpStatusCode int    `json:"httpStatusCode"`
+}
+
+// objectJSON is an entry of list responses. Folders only have a name.
+type objectJSON struct {
+	Name           string                 `json:"name"`
+	BucketId       string                 `json:"bucket_id,omitempty"`
+	Id             *string                `json:"id"`
+	UpdatedAt      *string                `json:"updated_at"`
+	CreatedAt      *string                `json:"created_at"`
+	LastAccessedAt *string                `json:"last_accessed_at"`
+	Metadata       *objectMetadata        `json:"metadata"`
+	UserMetadata   map[string]interface{} `json:"user_metadata,omitempty"`
+}
+
+func (o *object) toJSON(bucketId string, name string) objectJSON {
+	updatedAt := formatTime(o.updatedAt)
+	createdAt := formatTime(o.createdAt)
+	lastAccessedAt := formatTime(o.lastAccessedAt)
+	return objectJSON{
+		Name:           name,
+		BucketId:       bucketId,
+		Id:             &o.id,
+		UpdatedAt:      &updatedAt,
+		CreatedAt:      &createdAt,
+		LastAccessedAt: &lastAccessedAt,
+		Metadata: &objectMetadata{
+			ETag:           o.eTag,
+			Size:           len(o.data),
+			Mimetype:       o.contentType,
+			CacheControl:   o.cacheControl,
+			LastModified:   formatTime(o.updatedAt),
+			ContentLength:  len(o.data),
+			HttpStatusCode: http.StatusOK,
+		},
+		UserMetadata: o.userMetadata,
+	}
+}
+
+// lookupObject returns the bucket and object at path, writing the error response if either is missing.
+func (s *Server) lookupObject(w http.ResponseWriter, bucketId string, path string) (*bucket, *object, bool) {
+	b, ok := s.buckets[bucketId]
+	if !ok {
+		bucketNotFound(w)
+		return nil, nil, false
+	}
+	obj, ok := b.objects[path]
+	if !ok {
+		objectNotFound(w)
+		return nil, nil, false
+	}
+	return b, obj, true
+}
+
+func (s *Server) upload(w http.ResponseWriter, r *http.Request, bucketId string, path string, update bool) {
+	b, ok := s.buckets[bucketId]
+	if !ok {
+		bucketNotFound(w)
+		return
+	}
+	if path == "" {
+		writeError(w, http.StatusBadRequest, "InvalidKey", "Invalid key")
+		return
+	}
+	_, exists := b.objects[path]
+	upsert, _ := strconv.ParseBool(r.Header.Get("x-upsert"))
+	if update && !exists {
+		objectNotFound(w)
+		return
+	}
+	if !update && exists && !upsert {
+		writeError(w, http.StatusConflict, "Duplicate", "The resource already exists")
+		return
+	}
+	s.store(w, r, b, path)
+}
+
+// store saves the request body as the object at path after checking the bucket constraints.
+func (s *Server) store(w http.ResponseWriter, r *http.Request, b *bucket, path string) {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = defaultContentType
+	}
+	if !mimeTypeAllowed(b.allowedMimeTypes, contentType) {
+		writeError(w, http.StatusUnsupportedMediaType, "invalid_mime_type", "mime type "+contentType+" is not supported")
+		return
+	}
+
+	userMetadata, ok := parseUserMetadata(w, r)
+	if !ok {
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Could not read the request body")
+		return
+	}
+	if b.fileSizeLimit != nil && int64(len(data)) > *b.fileSizeLimit {
+		writeError(w, http.StatusRequestEntityTooLarge, "Payload too large", "The object exceeded the maximum allowed size")
+		return
+	}
+
+	cacheControl := defaultCacheControl
+	if value := r.Header.Get("Cache-Control"); value != "" {
+		cacheControl = value
+		if _, err := strconv.Atoi(value); err == nil {
+			cacheControl = "max-age=" + value
+		}
+	}
+
+	now := s.now()
+	obj, exists := b.objects[path]
+	if !exists {
+		obj = &object{id: newID(), createdAt: now}
+		b.objects[path] = obj
+	}
+	sum := md5.Sum(data)
+	obj.version = newID()
+	obj.data = data
+	obj.contentType = contentType
+	obj.cacheControl = cacheControl
+	obj.userMetadata = userMetadata
+	obj.eTag = `"` + hex.EncodeToString(sum[:]) + `"`
+	obj.updatedAt = now
+	obj.lastAccessedAt = now
+
+	writeJSON(w, http.StatusOK, map[string]string{"Id": obj.id, "Key": b.id + "/" + path})
+}
+
+// mimeTypeAllowed matches contentType against allowed, which may contain wildcards like `image/*`.
+func mimeTypeAllowed(allowed []string, contentType string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+	for _, pattern := range allowed {
+		if pattern == mediaType || pattern == "*/*" {
+			return true
+		}
+		if strings.HasSuffix(pattern, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) download(w http.ResponseWriter, r *http.Request, bucketId string, path string) {
+	_, obj, ok := s.lookupObject(w, bucketId, path)
+	if !ok {
+		return
+	}
+	s.serveObject(w, r, path, obj)
+}
+
+func (s *Server) downloadPublic(w http.ResponseWriter, r *http.Request, bucketId string, path string) {
+	if b, ok := s.buckets[bucketId]; !ok || !b.public {
+		bucketNotFound(w)
+		return
+	}
+	s.download(w, r, bucketId, path)
+}
+
+func (s *Server) serveObject(w http.ResponseWriter, r *http.Request, path string, obj *object) {
+	obj.lastAccessedAt = s.now()
+	w.Header().Set("Content-Type", obj.contentType)
+	w.Header().Set("Cache-Control", obj.cacheControl)
+	w.Header().Set("ETag", obj.eTag)
+	if query := r.URL.Query(); query.Has("download") {
+		disposition := "attachment"
+		if filename := query.Get("download"); filename != "" && filename != "true" {
+			disposition = mime.FormatMediaType("attachment", map[string]string{"filename": filename})
+		}
+		w.Header().Set("Content-Disposition", disposition)
+	}
+	http.ServeContent(w, r, path, obj.updatedAt, bytes.NewReader(obj.data))
+}
+
+func (s *Server) removeObjects(w http.ResponseWriter, r *http.Request, bucketId string) {
+	b, ok := s.buckets[bucketId]
+	if !ok {
+		bucketNotFound(w)
+		return
+	}
+	var req struct {
+		Prefixes []string `json:"prefixes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Invalid request body")
+		return
+	}
+
+	removed := []objectJSON{}
+	for _, path := range req.Prefixes {
+		if obj, ok := b.objects[path]; ok {
+			removed = append(removed, obj.toJSON(bucketId, path))
+			delete(b.objects, path)
+		}
+	}
+	writeJSON(w, http.StatusOK, removed)
+}
+
+// objectInfoJSON is the body of the info route.
+type objectInfoJSON struct {
+	Id           string      `json:"id"`
+	Name         string      `json:"name"`
+	Version      string      `json:"version"`
+	BucketId     string      `json:"bucket_id"`
+	Size         int         `json:"size"`
+	ContentType  string      `json:"content_type"`
+	CacheControl string      `json:"cache_control"`
+	ETag         string      `json:"etag"`
+	Metadata     interface{} `json:"metadata"`
+	LastModified string      `json:"last_modified"`
+	CreatedAt    string      `json:"created_at"`
+}
+
+func (s *Server) objectInfo(w http.ResponseWriter, bucketId string, path string) {
+	_, obj, ok := s.lookupObject(w, bucketId, path)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, objectInfoJSON{
+		Id:           obj.id,
+		Name:         path,
+		Version:      obj.version,
+		BucketId:     bucketId,
+		Size:         len(obj.data),
+		ContentType:  obj.contentType,
+		CacheControl: obj.cacheControl,
+		ETag:         obj.eTag,
+		LastModified: formatTime(obj.updatedAt),
+		CreatedAt:    formatTime(obj.createdAt),
+		Metadata:     obj.userMetadata,
+	})
+}
+
+// parseUserMetadata decodes the base64 JSON object of the x-metadata header,
+// writing the error response if it is invalid.
+func parseUserMetadata(w http.ResponseWriter, r *http.Request) (map[string]interface{}, bool) {
+	header := r.Header.Get("x-metadata")
+	if header == "" {
+		return nil, true
+	}
+	var metadata map[string]interface{}
+	data, err := base64.StdEncoding.DecodeString(header)
+	if err == nil {
+		err = json.Unmarshal(data, &metadata)
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Invalid x-metadata header")
+		return nil, false
+	}
+	return metadata, true
+}
+
+// listRequest is the body of the list route.
+type listRequest struct {
+	Prefix string `json:"prefix"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+	SortBy struct {
+		Column string `json:"column"`
+		Order  string `json:"order"`
+	} `json:"sortBy"`
+}
+
+// listObjects lists the files and folders directly under the prefix, like the
+// Storage API does: folders are returned with only a name.
+func (s *Server) listObjects(w http.ResponseWriter, r *http.Request, bucketId string) {
+	b, ok := s.buckets[bucketId]
+	if !ok {
+		bucketNotFound(w)
+		return
+	}
+	var req listRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Invalid request body")
+		return
+	}
+	if req.Limit <= 0 {
+		req.Limit = 100
+	}
+
+	prefix := strings.Trim(req.Prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	type entry struct {
+		name string
+		obj  *object
+	}
+	var entries []entry
+	folders := map[string]bool{}
+	for path, obj := range b.objects {
+		if !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		name := strings.TrimPrefix(path, prefix)
+		if i := strings.Index(name, "/"); i >= 0 {
+			folder := name[:i]
+			if !folders[folder] {
+				folders[folder] = true
+				entries = append(entries, entry{name: folder})
+			}
+			continue
+		}
+		entries = append(entries, entry{name: name, obj: obj})
+	}
+
+	descending := strings.EqualFold(req.SortBy.Order, "desc")
+	sortKey := func(e entry) string {
+		if e.obj == nil {
+			return ""
+		}
+		switch req.SortBy.Column {
+		case "created_at":
+			return formatTime(e.obj.createdAt)
+		case "updated_at":
+			return formatTime(e.obj.updatedAt)
+		case "last_accessed_at":
+			return formatTime(e.obj.lastAccessedAt)
+		}
+		return ""
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if descending {
+			a, b = b, a
+		}
+		if ka, kb := sortKey(a), sortKey(b); ka != kb {
+			return ka < kb
+		}
+		return a.name < b.name
+	})
+
+	if req.Offset > len(entries) {
+		req.Offset = len(entries)
+	}
+	entries = entries[req.Offset:]
+	if len(entries) > req.Limit {
+		entries = entries[:req.Limit]
+	}
+
+	list := make([]objectJSON, 0, len(entries))
+	for _, e := range entries {
+		if e.obj == nil {
+			list = append(list, objectJSON{Name: e.name})
+			continue
+		}
+		list = append(list, e.obj.toJSON(bucketId, e.name))
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// listV2Request is the body of the list-v2 route.
+type listV2Request struct {
+	Prefix        string `json:"prefix"`
+	Limit         int    `json:"limit"`
+	Cursor        string `json:"cursor"`
+	WithDelimiter bool   `json:"with_delimiter"`
+	SortBy        *struct {
+		Column string `json:"column"`
+		Order  string `json:"order"`
+	} `json:"sortBy"`
+}
+
+type folderJSON struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+type listV2Response struct {
+	HasNext    bool         `json:"hasNext"`
+	NextCursor string       `json:"nextCursor,omitempty"`
+	Folders    []folderJSON `json:"folders"`
+	Objects    []objectJSON `json:"objects"`
+}
+
+// listObjectsV2 lists the objects under the prefix ordered by name, paging
+// with an opaque cursor holding the last name of the previous page. With a
+// delimiter, the folders directly under the prefix are returned instead of
+// their objects.
+func (s *Server) listObjectsV2(w http.ResponseWriter, r *http.Request, bucketId string) {
+	b, ok := s.buckets[bucketId]
+	if !ok {
+		bucketNotFound(w)
+		return
+	}
+	var req listV2Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Invalid request body")
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 1000 {
+		req.Limit = 1000
+	}
+	if req.SortBy != nil && req.SortBy.Column != "" && req.SortBy.Column != "name" {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Only sorting by name is supported")
+		return
+	}
+	descending := req.SortBy != nil && strings.EqualFold(req.SortBy.Order, "desc")
+	var after string
+	if req.Cursor != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(req.Cursor)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "InvalidRequest", "Invalid cursor")
+			return
+		}
+		after = string(decoded)
+	}
+
+	var names []string
+	folders := map[string]bool{}
+	for path := range b.objects {
+		if !strings.HasPrefix(path, req.Prefix) {
+			continue
+		}
+		if req.WithDelimiter {
+			rest := strings.TrimPrefix(path, req.Prefix)
+			if i := strings.Index(rest, "/"); i >= 0 {
+				folder := req.Prefix + rest[:i+1]
+				if !folders[folder] {
+					folders[folder] = true
+					names = append(names, folder)
+				}
+				continue
+			}
+		}
+		names = append(names, path)
+	}
+	sort.Strings(names)
+	if descending {
+		sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	}
+
+	if after != "" {
+		start := len(names)
+		for i, name := range names {
+			if (!descending && name > after) || (descending && name < after) {
+				start = i
+				break
+			}
+		}
+		names = names[start:]
+	}
+
+	resp := listV2Response{
+		Folders: []folderJSON{},
+		Objects: []objectJSON{},
+	}
+	if len(names) > req.Limit {
+		names = names[:req.Limit]
+		resp.HasNext = true
+		resp.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(names[len(names)-1]))
+	}
+	for _, name := range names {
+		if folders[name] {
+			resp.Folders = append(resp.Folders, folderJSON{Name: name, Key: name})
+			continue
+		}
+		resp.Objects = append(resp.Objects, b.objects[name].toJSON("", name))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// moveCopyRequest is the body of the move and copy routes.
+type moveCopyRequest struct {
+	BucketId          string `json:"bucketId"`
+	SourceKey         string `json:"sourceKey"`
+	DestinationKey    string `json:"destinationKey"`
+	DestinationBucket string `json:"destinationBucket"`
+	// CopyMetadata false replaces the metadata of a copy with Metadata.
+	CopyMetadata *bool `json:"copyMetadata"`
+	Metadata     *struct {
+		CacheControl string `json:"cacheControl"`
+		Mimetype     string `json:"mimetype"`
+	} `json:"metadata"`
+}
+
+// transfer copies the source object of req to its destination and returns both buckets.
+func (s *Server) transfer(w http.ResponseWriter, r *http.Request) (moveCopyRequest, *bucket, *object, bool) {
+	var req moveCopyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Invalid request body")
+		return req, nil, nil, false
+	}
+	_, src, ok := s.lookupObject(w, req.BucketId, req.SourceKey)
+	if !ok {
+		return req, nil, nil, false
+	}
+	if req.DestinationBucket == "" {
+		req.DestinationBucket = req.BucketId
+	}
+	dst, ok := s.buckets[req.DestinationBucket]
+	if !ok {
+		bucketNotFound(w)
+		return req, nil, nil, false
+	}
+	upsert, _ := strconv.ParseBool(r.Header.Get("x-upsert"))
+	if _, exists := dst.objects[req.DestinationKey]; exists && !upsert {
+		writeError(w, http.StatusConflict, "Duplicate", "The resource already exists")
+		return req, nil, nil, false
+	}
+
+	now := s.now()
+	copied := *src
+	copied.id = newID()
+	copied.version = newID()
+	copied.data = append([]byte(nil), src.data...)
+	copied.createdAt = now
+	copied.updatedAt = now
+	copied.lastAccessedAt = now
+	dst.objects[req.DestinationKey] = &copied
+
+	return req, dst, &copied, true
+}
+
+func (s *Server) moveObject(w http.ResponseWriter, r *http.Request) {
+	req, _, moved, ok := s.transfer(w, r)
+	if !ok {
+		return
+	}
+	src := s.buckets[req.BucketId]
+	moved.id = src.objects[req.SourceKey].id
+	if req.BucketId != req.DestinationBucket || req.SourceKey != req.DestinationKey {
+		delete(src.objects, req.SourceKey)
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"message": "Successfully moved"})
+}
+
+func (s *Server) copyObject(w http.ResponseWriter, r *http.Request) {
+	userMetadata, ok := parseUserMetadata(w, r)
+	if !ok {
+		return
+	}
+	req, dst, copied, ok := s.transfer(w, r)
+	if !ok {
+		return
+	}
+	if userMetadata != nil {
+		copied.userMetadata = userMetadata
+	}
+	if req.CopyMetadata != nil && !*req.CopyMetadata && req.Metadata != nil {
+		if req.Metadata.CacheControl != "" {
+			copied.cacheControl = req.Metadata.CacheControl
+		}
+		if req.Metadata.Mimetype != "" {
+			copied.contentType = req.Metadata.Mimetype
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"Id": copied.id, "Key": dst.id + "/" + req.DestinationKey})
+}
+
+// createSignedUrl signs a single path, or the paths of the body when the
+// route has no path.
+func (s *Server) createSignedUrl(w http.ResponseWriter, r *http.Request, bucketId string, path string) {
+	var req struct {
+		ExpiresIn int             `json:"expiresIn"`
+		Paths     []string        `json:"paths"`
+		Transform json.RawMessage `json:"transform"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Invalid request body")
+		return
+	}
+	if req.ExpiresIn <= 0 {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "body/expiresIn must be >= 1")
+		return
+	}
+	expiresIn := time.Duration(req.ExpiresIn) * time.Second
+
+	if path == "" {
+		b, ok := s.buckets[bucketId]
+		if !ok {
+			bucketNotFound(w)
+			return
+		}
+		if len(req.Paths) == 0 {
+			writeError(w, http.StatusBadRequest, "InvalidRequest", "body/paths must NOT have fewer than 1 items")
+			return
+		}
+		results := make([]map[string]*string, 0, len(req.Paths))
+		for _, p := range req.Paths {
+			p := p
+			if _, exists := b.objects[p]; !exists {
+				message := "Either the object does not exist or you do not have access to it"
+				results = append(results, map[string]*string{"path": &p, "signedURL": nil, "error": &message})
+				continue
+			}
+			signedURL := s.signPath(bucketId, p, (&url.URL{Path: bucketId + "/" + p}).EscapedPath(), expiresIn, nil)
+			results = append(results, map[string]*string{"path": &p, "signedURL": &signedURL, "error": nil})
+		}
+		writeJSON(w, http.StatusOK, results)
+		return
+	}
+
+	if _, _, ok := s.lookupObject(w, bucketId, path); !ok {
+		return
+	}
+	var transform *transformRequest
+	if len(req.Transform) > 0 && string(req.Transform) != "null" {
+		transform = &transformRequest{}
+		if err := json.Unmarshal(req.Transform, transform); err != nil {
+			writeError(w, http.StatusBadRequest, "InvalidRequest", "Invalid request body")
+			return
+		}
+	}
+	escapedPath := r.URL.EscapedPath()
+	escapedPath = escapedPath[strings.Index(escapedPath, "/sign/")+len("/sign/"):]
+	writeJSON(w, http.StatusOK, map[string]string{
+		"signedURL": s.signPath(bucketId, path, escapedPath, expiresIn, transform),
+	})
+}
+
+// signPath returns the signed download URL of path, relative to the server.
+// escapedPath is `bucket/path` escaped as in a request path. With transform,
+// the URL is a render/image route.
+func (s *Server) signPath(bucketId string, path string, escapedPath string, expiresIn time.Duration, transform *transformRequest) string {
+	if s.jwtSecret != nil {
+		return s.signJWT(escapedPath, expiresIn, transform)
+	}
+	token := newToken()
+	s.tokens[token] = signedToken{
+		bucketId:  bucketId,
+		path:      path,
+		transform: transform != nil,
+		expiresAt: s.now().Add(expiresIn),
+	}
+	route := "/object/sign/"
+	if transform != nil {
+		route = "/render/image/sign/"
+	}
+	return route + bucketId + "/" + path + "?token=" + token
+}
+
+// validToken reports whether the token of r grants access to path in bucketId.
+func (s *Server) validToken(w http.ResponseWriter, r *http.Request, bucketId string, path string, upload bool, transform bool) bool {
+	if s.jwtSecret != nil && !upload {
+		return s.validJWT(w, r, transform)
+	}
+	token, ok := s.tokens[r.URL.Query().Get("token")]
+	if !ok || token.bucketId != bucketId || token.path != path || token.upload != upload || token.transform != transform {
+		writeError(w, http.StatusBadRequest, "InvalidJWT", "invalid signature")
+		return false
+	}
+	if s.now().After(token.expiresAt) {
+		writeError(w, http.StatusBadRequest, "InvalidJWT", "jwt expired")
+		return false
+	}
+	return true
+}
+
+func (s *Server) downloadSigned(w http.ResponseWriter, r *http.Request, bucketId string, path string) {
+	if !s.validToken(w, r, bucketId, path, false, false) {
+		return
+	}
+	s.download(w, r, bucketId, path)
+}
+
+func (s *Server) createSignedUploadUrl(w http.ResponseWriter, bucketId string, path string) {
+	b, ok := s.buckets[bucketId]
+	if !ok {
+		bucketNotFound(w)
+		return
+	}
+	if _, exists := b.objects[path]; exists {
+		writeError(w, http.StatusConflict, "Duplicate", "The resource already exists")
+		return
+	}
+
+	token := newToken()
+	s.tokens[token] = signedToken{
+		bucketId:  bucketId,
+		path:      path,
+		upload:    true,
+		expiresAt: s.now().Add(2 * time.Hour),
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"url":   "/object/upload/sign/" + bucketId + "/" + path + "?token=" + token,
+		"token": token,
+	})
+}
+
+func (s *Server) uploadToSignedUrl(w http.ResponseWriter, r *http.Request, bucketId string, path string) {
+	if !s.validToken(w, r, bucketId, path, true, false) {
+		return
+	}
+	b, ok := s.buckets[bucketId]
+	if !ok {
+		bucketNotFound(w)
+		return
+	}
+	upsert, _ := strconv.ParseBool(r.Header.Get("x-upsert"))
+	if _, exists := b.objects[path]; exists && !upsert {
+		writeError(w, http.StatusConflict, "Duplicate", "The resource already exists")
+		return
+	}
+	s.store(w, r, b, path)
+}
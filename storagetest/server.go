@@ -0,0 +1,338 @@
+// Package storagetest provides an in-memory fake of the Supabase Storage API
+// for tests. It implements the bucket and object routes used by storage_go's
+// Client, enforces bucket constraints and answers with the same error bodies
+// as the real server, so code built on the client can be tested without a
+// Supabase project:
+//
+//	srv := storagetest.NewServer()
+//	defer srv.Close()
+//
+//	client := srv.NewClient()
+//	_, err := client.CreateBucket("avatars", storage_go.BucketOptions{Public: true})
+//
+// Authorization headers are accepted without being checked.
+package storagetest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	storage_go "github.com/supabase-community/storage-go"
+)
+
+const timeFormat = "2006-01-02T15:04:05.000Z"
+
+// Server is a fake Storage API listening on a local address.
+type Server struct {
+	// URL is the base URL of the Storage API, to be passed to storage_go.NewClient.
+	URL string
+
+	server *httptest.Server
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	tokens    map[string]signedToken
+	jwtSecret []byte
+	now       func() time.Time
+}
+
+type bucket struct {
+	id               string
+	public           bool
+	fileSizeLimit    *int64
+	allowedMimeTypes []string
+	createdAt        time.Time
+	updatedAt        time.Time
+	objects          map[string]*object
+}
+
+type object struct {
+	id             string
+	version        string
+	data           []byte
+	contentType    string
+	cacheControl   string
+	userMetadata   map[string]interface{}
+	eTag           string
+	createdAt      time.Time
+	updatedAt      time.Time
+	lastAccessedAt time.Time
+}
+
+// signedToken grants access to a single object until it expires.
+type signedToken struct {
+	bucketId  string
+	path      string
+	upload    bool
+	transform bool
+	expiresAt time.Time
+}
+
+// NewServer starts a fake Storage API with no buckets. Call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		buckets: map[string]*bucket{},
+		tokens:  map[string]signedToken{},
+		now:     time.Now,
+	}
+	s.server = httptest.NewServer(s)
+	s.URL = s.server.URL
+	return s
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// NewClient returns a storage_go.Client connected to the server.
+func (s *Server) NewClient() *storage_go.Client {
+	return storage_go.NewClient(s.URL, "storagetest", nil)
+}
+
+// Object returns the content of the object at path in bucketId, if it exists.
+func (s *Server) Object(bucketId string, path string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buckets[bucketId]
+	if !ok {
+		return nil, false
+	}
+	obj, ok := b.objects[path]
+	if !ok {
+		return nil, false
+	}
+	return append([]byte(nil), obj.data...), true
+}
+
+// ServeHTTP routes a Storage API request.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Read the body before taking the lock so that a slow upload does not
+	// hold up the other requests.
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidRequest", "Could not read the request body")
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	switch {
+	case path == "bucket":
+		switch r.Method {
+		case http.MethodGet:
+			s.listBuckets(w, r)
+		case http.MethodPost:
+			s.createBucket(w, r)
+		default:
+			methodNotAllowed(w)
+		}
+	case strings.HasPrefix(path, "bucket/"):
+		s.routeBucket(w, r, strings.TrimPrefix(path, "bucket/"))
+	case strings.HasPrefix(path, "object/"):
+		s.routeObject(w, r, strings.TrimPrefix(path, "object/"))
+	case strings.HasPrefix(path, "render/image/"):
+		s.routeRender(w, r, strings.TrimPrefix(path, "render/image/"))
+	default:
+		writeError(w, http.StatusNotFound, "not_found", "Route "+r.Method+":"+r.URL.Path+" not found")
+	}
+}
+
+func (s *Server) routeBucket(w http.ResponseWriter, r *http.Request, path string) {
+	if id := strings.TrimSuffix(path, "/empty"); id != path {
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w)
+			return
+		}
+		s.emptyBucket(w, id)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.getBucket(w, path)
+	case http.MethodPut:
+		s.updateBucket(w, r, path)
+	case http.MethodDelete:
+		s.deleteBucket(w, path)
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+func (s *Server) routeObject(w http.ResponseWriter, r *http.Request, path string) {
+	switch {
+	case path == "move" && r.Method == http.MethodPost:
+		s.moveObject(w, r)
+	case path == "copy" && r.Method == http.MethodPost:
+		s.copyObject(w, r)
+	case strings.HasPrefix(path, "list-v2/") && r.Method == http.MethodPost:
+		s.listObjectsV2(w, r, strings.TrimPrefix(path, "list-v2/"))
+	case strings.HasPrefix(path, "list/") && r.Method == http.MethodPost:
+		s.listObjects(w, r, strings.TrimPrefix(path, "list/"))
+	case strings.HasPrefix(path, "info/"):
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w)
+			return
+		}
+		bucketId, objectPath := splitObjectPath(strings.TrimPrefix(path, "info/"))
+		s.objectInfo(w, bucketId, objectPath)
+	case strings.HasPrefix(path, "upload/sign/"):
+		bucketId, objectPath := splitObjectPath(strings.TrimPrefix(path, "upload/sign/"))
+		switch r.Method {
+		case http.MethodPost:
+			s.createSignedUploadUrl(w, bucketId, objectPath)
+		case http.MethodPut:
+			s.uploadToSignedUrl(w, r, bucketId, objectPath)
+		default:
+			methodNotAllowed(w)
+		}
+	case strings.HasPrefix(path, "sign/"):
+		bucketId, objectPath := splitObjectPath(strings.TrimPrefix(path, "sign/"))
+		switch r.Method {
+		case http.MethodPost:
+			s.createSignedUrl(w, r, bucketId, objectPath)
+		case http.MethodGet, http.MethodHead:
+			s.downloadSigned(w, r, bucketId, objectPath)
+		default:
+			methodNotAllowed(w)
+		}
+	case strings.HasPrefix(path, "public/"):
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			methodNotAllowed(w)
+			return
+		}
+		bucketId, objectPath := splitObjectPath(strings.TrimPrefix(path, "public/"))
+		s.downloadPublic(w, r, bucketId, objectPath)
+	case strings.HasPrefix(path, "authenticated/"):
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			methodNotAllowed(w)
+			return
+		}
+		bucketId, objectPath := splitObjectPath(strings.TrimPrefix(path, "authenticated/"))
+		s.download(w, r, bucketId, objectPath)
+	default:
+		bucketId, objectPath := splitObjectPath(path)
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			s.download(w, r, bucketId, objectPath)
+		case http.MethodPost:
+			s.upload(w, r, bucketId, objectPath, false)
+		case http.MethodPut:
+			s.upload(w, r, bucketId, objectPath, true)
+		case http.MethodDelete:
+			if objectPath != "" {
+				methodNotAllowed(w)
+				return
+			}
+			s.removeObjects(w, r, bucketId)
+		default:
+			methodNotAllowed(w)
+		}
+	}
+}
+
+// routeRender serves the image transformation routes. Images are not
+// transformed: the original object is returned.
+func (s *Server) routeRender(w http.ResponseWriter, r *http.Request, path string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		methodNotAllowed(w)
+		return
+	}
+	switch {
+	case strings.HasPrefix(path, "public/"):
+		bucketId, objectPath := splitObjectPath(strings.TrimPrefix(path, "public/"))
+		s.downloadPublic(w, r, bucketId, objectPath)
+	case strings.HasPrefix(path, "authenticated/"):
+		bucketId, objectPath := splitObjectPath(strings.TrimPrefix(path, "authenticated/"))
+		s.download(w, r, bucketId, objectPath)
+	case strings.HasPrefix(path, "sign/"):
+		bucketId, objectPath := splitObjectPath(strings.TrimPrefix(path, "sign/"))
+		if !s.validToken(w, r, bucketId, objectPath, false, true) {
+			return
+		}
+		s.download(w, r, bucketId, objectPath)
+	default:
+		writeError(w, http.StatusNotFound, "not_found", "Route "+r.Method+":"+r.URL.Path+" not found")
+	}
+}
+
+// splitObjectPath splits `bucket/folder/file.png` into the bucket id and object path.
+func splitObjectPath(path string) (string, string) {
+	i := strings.Index(path, "/")
+	if i < 0 {
+		return path, ""
+	}
+	return path[:i], path[i+1:]
+}
+
+// errorBody mirrors the error responses of the Storage API.
+type errorBody struct {
+	StatusCode string `json:"statusCode"`
+	Error      string `json:"error"`
+	Message    string `json:"message"`
+}
+
+func writeError(w http.ResponseWriter, status int, code string, message string) {
+	writeBodyStatusError(w, status, status, code, message)
+}
+
+// writeBodyStatusError answers with status but reports bodyStatus in the
+// body, as the Storage API does for some errors.
+func writeBodyStatusError(w http.ResponseWriter, status int, bodyStatus int, code string, message string) {
+	writeJSON(w, status, errorBody{
+		StatusCode: strconv.Itoa(bodyStatus),
+		Error:      code,
+		Message:    message,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func methodNotAllowed(w http.ResponseWriter) {
+	writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+}
+
+// bucketNotFound and objectNotFound answer like the Storage API, with a 400
+// whose body holds the actual status, 404.
+func bucketNotFound(w http.ResponseWriter) {
+	writeBodyStatusError(w, http.StatusBadRequest, http.StatusNotFound, "Bucket not found", "Bucket not found")
+}
+
+func objectNotFound(w http.ResponseWriter) {
+	writeBodyStatusError(w, http.StatusBadRequest, http.StatusNotFound, "not_found", "Object not found")
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	h := hex.EncodeToString(b)
+	return fmt.Sprintf("%s-%s-%s-%s-%s", h[:8], h[8:12], h[12:16], h[16:20], h[20:])
+}
+
+func newToken() string {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
@@ -0,0 +1,238 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	storage_go "github.com/supabase-community/storage-go"
+)
+
+// closingReader records whether it was closed.
+type closingReader struct {
+	*strings.Reader
+	closed int32
+}
+
+func (r *closingReader) Close() error {
+	atomic.StoreInt32(&r.closed, 1)
+	return nil
+}
+
+func sendJobs(paths ...string) <-chan storage_go.UploadJob {
+	jobs := make(chan storage_go.UploadJob)
+	go func() {
+		defer close(jobs)
+		for _, path := range paths {
+			jobs <- storage_go.UploadJob{Path: path, Data: strings.NewReader(path)}
+		}
+	}()
+	return jobs
+}
+
+func TestBatchUpload(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	var inFlight, maxInFlight int32
+	c, err := storage_go.NewClientWithOptions(srv.URL, storage_go.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				highest := atomic.LoadInt32(&maxInFlight)
+				if n <= highest || atomic.CompareAndSwapInt32(&maxInFlight, highest, n) {
+					break
+				}
+			}
+			time.Sleep(2 * time.Millisecond)
+			return next.RoundTrip(req)
+		})
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for i := 0; i < 40; i++ {
+		paths = append(paths, fmt.Sprintf("assets/%02d.txt", i))
+	}
+	report, err := c.NewBatchUploader("test", storage_go.BatchUploadOptions{Concurrency: 5}).Upload(sendJobs(paths...))
+	if err != nil {
+		t.Fatalf("Upload failed: %v", err)
+	}
+	if report.Succeeded != 40 || report.Failed != 0 || report.Skipped != 0 || len(report.Results) != 40 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	for i, result := range report.Results {
+		if result.Path != paths[i] || result.Key != "test/"+paths[i] {
+			t.Errorf("unexpected result %d: %+v", i, result)
+		}
+		if data, ok := srv.Object("test", paths[i]); !ok || string(data) != paths[i] {
+			t.Errorf("unexpected content of %s: %q", paths[i], data)
+		}
+	}
+	if highest := atomic.LoadInt32(&maxInFlight); highest > 5 || highest < 2 {
+		t.Errorf("expected between 2 and 5 uploads in parallel, got %d", highest)
+	}
+}
+
+func TestBatchUploadBestEffort(t *testing.T) {
+	_, c := newTestServer(t)
+	uploadDummy(t, c, "b.txt")
+
+	report, err := c.NewBatchUploader("test").Upload(sendJobs("a.txt", "b.txt", "c.txt"))
+	if !errors.Is(err, storage_go.ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+	if report.Succeeded != 2 || report.Failed != 1 || report.Skipped != 0 {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if !errors.Is(report.Results[1].Err, storage_go.ErrConflict) {
+		t.Errorf("expected the second job to fail, got %+v", report.Results)
+	}
+}
+
+func TestBatchUploadFailFast(t *testing.T) {
+	_, c := newTestServer(t)
+	uploadDummy(t, c, "b.txt")
+
+	jobs := make(chan storage_go.UploadJob, 4)
+	readers := []*closingReader{}
+	for _, path := range []string{"a.txt", "b.txt", "c.txt", "d.txt"} {
+		r := &closingReader{Reader: strings.NewReader(path)}
+		readers = append(readers, r)
+		jobs <- storage_go.UploadJob{Path: path, Data: r}
+	}
+	close(jobs)
+
+	report, err := c.NewBatchUploader("test", storage_go.BatchUploadOptions{Concurrency: 1, FailFast: true}).Upload(jobs)
+	if !errors.Is(err, storage_go.ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+	if report.Succeeded != 1 || report.Failed != 1 || report.Skipped != 2 {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if report.Results[3].Err != storage_go.ErrUploadSkipped {
+		t.Errorf("expected the last job to be skipped, got %v", report.Results[3].Err)
+	}
+	for i, r := range readers {
+		if atomic.LoadInt32(&r.closed) != 1 {
+			t.Errorf("expected the data of job %d to be closed", i)
+		}
+	}
+}
+
+func TestBatchUploadFailFastCancelsInProgress(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	started := make(chan struct{}, 3)
+	c, err := storage_go.NewClientWithOptions(srv.URL, storage_go.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path == "/object/test/fail.txt" {
+				// Fail once the other uploads are in progress.
+				<-started
+				<-started
+				return &http.Response{StatusCode: http.StatusBadRequest, Body: http.NoBody, Header: http.Header{}, Request: req}, nil
+			}
+			started <- struct{}{}
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		})
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := c.NewBatchUploader("test", storage_go.BatchUploadOptions{Concurrency: 3, FailFast: true}).Upload(sendJobs("a.txt", "fail.txt", "b.txt"))
+	if err == nil || errors.Is(err, context.Canceled) {
+		t.Errorf("expected the error of the failed upload, got %v", err)
+	}
+	if report.Failed != 1 || report.Skipped != 2 || report.Results[1].Err == nil || report.Results[1].Err == storage_go.ErrUploadSkipped {
+		t.Errorf("expected only the failed upload to be counted as failed, got %+v", report)
+	}
+}
+
+func TestBatchUploadCancelInProgress(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	started := make(chan struct{}, 2)
+	c, err := storage_go.NewClientWithOptions(srv.URL, storage_go.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			started <- struct{}{}
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		})
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		<-started
+		cancel()
+	}()
+	report, err := c.NewBatchUploader("test", storage_go.BatchUploadOptions{Concurrency: 2}).UploadWithContext(ctx, sendJobs("a.txt", "b.txt", "c.txt"))
+	if err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if report.Skipped != 3 || report.Failed != 0 {
+		t.Errorf("expected the cancelled uploads to be skipped, got %+v", report)
+	}
+	for _, result := range report.Results {
+		if result.Err != storage_go.ErrUploadSkipped {
+			t.Errorf("%s: expected ErrUploadSkipped, got %v", result.Path, result.Err)
+		}
+	}
+}
+
+func TestBatchUploadRetry(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	var mu sync.Mutex
+	attempts := map[string]int{}
+	c, err := storage_go.NewClientWithOptions(srv.URL, storage_go.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			attempts[req.URL.Path]++
+			first := attempts[req.URL.Path] == 1
+			mu.Unlock()
+			if first {
+				return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody, Header: http.Header{}, Request: req}, nil
+			}
+			return next.RoundTrip(req)
+		})
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := c.NewBatchUploader("test", storage_go.BatchUploadOptions{RetryPolicy: &fastRetryPolicy}).Upload(sendJobs("a.txt", "b.txt"))
+	if err != nil || report.Succeeded != 2 {
+		t.Fatalf("expected the uploads to be retried, got %+v (%v)", report, err)
+	}
+	if attempts["/object/test/a.txt"] != 2 {
+		t.Errorf("expected 2 attempts, got %v", attempts)
+	}
+}
+
+func TestBatchUploadCancel(t *testing.T) {
+	_, c := newTestServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report, err := c.NewBatchUploader("test").UploadWithContext(ctx, sendJobs("a.txt", "b.txt"))
+	if err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if report.Skipped+report.Failed != 2 || report.Succeeded != 0 {
+		t.Errorf("unexpected report %+v", report)
+	}
+}
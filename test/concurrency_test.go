@@ -0,0 +1,106 @@
+package test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	storage_go "github.com/supabase-community/storage-go"
+)
+
+// TestConcurrentRequestHeaders runs uploads with different options alongside
+// JSON calls on one Client; run with -race to also check for data races.
+func TestConcurrentRequestHeaders(t *testing.T) {
+	var mu sync.Mutex
+	var failures []string
+	fail := func(format string, args ...interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		failures = append(failures, fmt.Sprintf(format, args...))
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/bucket":
+			if got := r.Header.Get("Content-Type"); got != "application/json" {
+				fail("list buckets: content-type %q", got)
+			}
+			if got := r.Header.Get("x-upsert"); got != "" {
+				fail("list buckets: unexpected x-upsert %q", got)
+			}
+			_, _ = w.Write([]byte("[]"))
+		case strings.HasPrefix(r.URL.Path, "/object/test/"):
+			want := "image/" + strings.TrimPrefix(r.URL.Path, "/object/test/")
+			if got := r.Header.Get("Content-Type"); got != want {
+				fail("upload: content-type %q, want %q", got, want)
+			}
+			if values := r.Header.Values("Content-Type"); len(values) != 1 {
+				fail("upload: content-type sent %d times", len(values))
+			}
+			_, _ = w.Write([]byte(`{"Key":"test"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := storage_go.NewClient(srv.URL, token, map[string]string{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		format := []string{"png", "jpeg", "webp", "gif"}[i%4]
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			contentType := "image/" + format
+			upsert := true
+			_, err := c.UploadFile("test", format, strings.NewReader("data"), storage_go.FileOptions{
+				ContentType: &contentType,
+				Upsert:      &upsert,
+			})
+			if err != nil {
+				fail("upload: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := c.ListBuckets(); err != nil {
+				fail("list buckets: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, f := range failures {
+		t.Error(f)
+	}
+}
+
+// TestFakeServerSlowUpload checks that an upload whose body is still being
+// sent does not block the other requests of the fake server.
+func TestFakeServerSlowUpload(t *testing.T) {
+	_, c := newTestServer(t)
+
+	body, writer := io.Pipe()
+	uploaded := make(chan error, 1)
+	go func() {
+		_, err := c.UploadFile("test", "slow.txt", body)
+		uploaded <- err
+	}()
+	if _, err := writer.Write([]byte("partial")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.ListBuckets(); err != nil {
+		t.Errorf("ListBuckets failed: %v", err)
+	}
+
+	writer.Close()
+	if err := <-uploaded; err != nil {
+		t.Errorf("UploadFile failed: %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	storage_go "github.com/supabase-community/storage-go"
+)
+
+func TestDownloadFileStream(t *testing.T) {
+	modified := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/object/videos/clip.mp4" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "video/mp4")
+		w.Header().Set("ETag", `"abc123"`)
+		w.Header().Set("Cache-Control", "max-age=3600")
+		w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+		w.Header().Set("Content-Length", "11")
+		_, _ = io.WriteString(w, "video bytes")
+	}))
+	defer srv.Close()
+
+	c := storage_go.NewClient(srv.URL, token, map[string]string{})
+	body, info, err := c.DownloadFileStream("videos", "clip.mp4")
+	if err != nil {
+		t.Fatalf("DownloadFileStream failed: %v", err)
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil || string(data) != "video bytes" {
+		t.Fatalf("unexpected body %q (%v)", data, err)
+	}
+	if info.ContentType != "video/mp4" || info.ContentLength != 11 || info.ETag != `"abc123"` ||
+		info.CacheControl != "max-age=3600" || !info.LastModified.Equal(modified) {
+		t.Errorf("unexpected object info %+v", info)
+	}
+
+	data, err = c.DownloadFile("videos", "clip.mp4")
+	if err != nil || string(data) != "video bytes" {
+		t.Errorf("DownloadFile returned %q (%v)", data, err)
+	}
+
+	if _, _, err := c.DownloadFileStream("videos", "missing.mp4"); err == nil {
+		t.Errorf("expected an error for a missing object")
+	}
+}
@@ -0,0 +1,87 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	storage_go "github.com/supabase-community/storage-go"
+)
+
+func TestStorageErrorDetails(t *testing.T) {
+	srv, c := newTestServer(t)
+
+	_, err := c.DownloadFile("test", "missing.txt")
+	if !errors.Is(err, storage_go.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	var storageErr *storage_go.StorageError
+	if !errors.As(err, &storageErr) {
+		t.Fatalf("expected a *StorageError, got %T", err)
+	}
+	// Like the Storage API, the fake answers with a 400 whose body holds the 404.
+	if storageErr.Status != http.StatusBadRequest || storageErr.Code != "not_found" || storageErr.Message != "Object not found" {
+		t.Errorf("unexpected error fields %+v", storageErr)
+	}
+	if storageErr.Method != http.MethodGet || storageErr.URL != srv.URL+"/object/test/missing.txt" {
+		t.Errorf("unexpected request %s %s", storageErr.Method, storageErr.URL)
+	}
+	if errors.Is(err, storage_go.ErrConflict) {
+		t.Errorf("did not expect ErrConflict")
+	}
+}
+
+func TestStorageErrorSentinels(t *testing.T) {
+	_, c := newTestServer(t)
+	uploadDummy(t, c, "a.txt")
+	if _, err := c.UploadFile("test", "a.txt", strings.NewReader("again")); !errors.Is(err, storage_go.ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+
+	if _, err := c.CreateBucket("small", storage_go.BucketOptions{FileSizeLimit: "1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.UploadFile("small", "a.txt", strings.NewReader("too large")); !errors.Is(err, storage_go.ErrPayloadTooLarge) {
+		t.Errorf("expected ErrPayloadTooLarge, got %v", err)
+	}
+}
+
+func TestStorageErrorStatusFromBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/bucket" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"statusCode":403,"error":"Unauthorized","message":"invalid signature"}`))
+			return
+		}
+		// Older servers report a missing object as a 400 with the status in the body.
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"statusCode":"404","error":"not_found","message":"Object not found"}`))
+	}))
+	defer srv.Close()
+
+	c := storage_go.NewClient(srv.URL, token, map[string]string{})
+	_, err := c.DownloadFile("test", "missing.txt")
+	if !errors.Is(err, storage_go.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	var storageErr *storage_go.StorageError
+	if errors.As(err, &storageErr) && storageErr.Status != http.StatusBadRequest {
+		t.Errorf("expected the HTTP status to be kept, got %d", storageErr.Status)
+	}
+
+	if _, err := c.ListBuckets(); !errors.Is(err, storage_go.ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized, got %v", err)
+	}
+}
+
+func TestInvalidBaseURL(t *testing.T) {
+	c := storage_go.NewClient("://missing-scheme", token, map[string]string{})
+	if _, err := c.ListBuckets(); err == nil || !strings.Contains(err.Error(), "invalid base URL") {
+		t.Errorf("expected the invalid base URL to be reported, got %v", err)
+	}
+	if _, err := c.UploadFile("test", "a.txt", strings.NewReader("data")); err == nil {
+		t.Errorf("expected the upload to fail")
+	}
+}
@@ -1,73 +1,238 @@
 package test
 
 import (
-	"fmt"
+	"errors"
+	"io"
+	"net/http"
 	"os"
+	"strings"
 	"testing"
 
 	storage_go "github.com/supabase-community/storage-go"
+	"github.com/supabase-community/storage-go/storagetest"
 )
 
-var (
-	rawUrl = "https://abc.supabase.co/storage/v1"
-	token  = ""
-)
+var token = ""
+
+// newTestServer starts a fake Storage server with an empty private bucket named "test".
+func newTestServer(t *testing.T) (*storagetest.Server, *storage_go.Client) {
+	t.Helper()
+	srv := storagetest.NewServer()
+	t.Cleanup(srv.Close)
+
+	c := storage_go.NewClient(srv.URL, token, map[string]string{})
+	if _, err := c.CreateBucket("test", storage_go.BucketOptions{}); err != nil {
+		t.Fatalf("CreateBucket failed: %v", err)
+	}
+	return srv, c
+}
+
+func uploadDummy(t *testing.T, c *storage_go.Client, path string) {
+	t.Helper()
+	file, err := os.Open("dummy.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := c.UploadFile("test", path, file); err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+}
 
 func TestUpload(t *testing.T) {
+	srv, c := newTestServer(t)
 	file, err := os.Open("dummy.txt")
 	if err != nil {
-		panic(err)
+		t.Fatal(err)
 	}
-	c := storage_go.NewClient(rawUrl, token, map[string]string{})
+	defer file.Close()
+
 	resp, err := c.UploadFile("test", "test.txt", file)
-	fmt.Println(resp, err)
+	if err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+	if resp.Key != "test/test.txt" {
+		t.Errorf("unexpected key %q", resp.Key)
+	}
+	want, _ := os.ReadFile("dummy.txt")
+	if got, ok := srv.Object("test", "test.txt"); !ok || string(got) != string(want) {
+		t.Errorf("unexpected stored content %q", got)
+	}
 
-	// resp, err = c.UploadFile("test", "hola.txt", []byte("hello world"))
-	// fmt.Println(resp, err)
+	if _, err := c.UploadFile("test", "test.txt", strings.NewReader("again")); err == nil {
+		t.Errorf("expected uploading an existing file without upsert to fail")
+	}
 }
 
 func TestUpdate(t *testing.T) {
-	file, err := os.Open("dummy.txt")
-	if err != nil {
-		panic(err)
-	}
-	c := storage_go.NewClient(rawUrl, token, map[string]string{})
-	resp, err := c.UpdateFile("test", "test.txt", file)
+	srv, c := newTestServer(t)
+	uploadDummy(t, c, "test.txt")
 
-	fmt.Println(resp, err)
+	if _, err := c.UpdateFile("test", "test.txt", strings.NewReader("updated")); err != nil {
+		t.Fatalf("UpdateFile failed: %v", err)
+	}
+	if got, _ := srv.Object("test", "test.txt"); string(got) != "updated" {
+		t.Errorf("unexpected stored content %q", got)
+	}
 }
 
 func TestMoveFile(t *testing.T) {
-	c := storage_go.NewClient(rawUrl, token, map[string]string{})
-	resp, err := c.MoveFile("test", "test.txt", "random/test.txt")
+	srv, c := newTestServer(t)
+	uploadDummy(t, c, "test.txt")
+
+	if _, err := c.MoveFile("test", "test.txt", "random/test.txt"); err != nil {
+		t.Fatalf("MoveFile failed: %v", err)
+	}
+	if _, ok := srv.Object("test", "test.txt"); ok {
+		t.Errorf("expected the source to be gone")
+	}
+	if _, ok := srv.Object("test", "random/test.txt"); !ok {
+		t.Errorf("expected the destination to exist")
+	}
+}
+
+func TestMoveFileToBucket(t *testing.T) {
+	srv, c := newTestServer(t)
+	uploadDummy(t, c, "test.txt")
+	if _, err := c.CreateBucket("archive", storage_go.BucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := c.MoveFile("test", "test.txt", "old/test.txt", storage_go.CopyOptions{DestinationBucket: "archive"})
+	if err != nil {
+		t.Fatalf("MoveFile failed: %v", err)
+	}
+	if resp.Key != "archive/old/test.txt" {
+		t.Errorf("unexpected key %q", resp.Key)
+	}
+	if _, ok := srv.Object("archive", "old/test.txt"); !ok {
+		t.Errorf("expected the destination to exist")
+	}
+	if _, ok := srv.Object("test", "test.txt"); ok {
+		t.Errorf("expected the source to be gone")
+	}
+}
+
+func TestCopyFile(t *testing.T) {
+	srv, c := newTestServer(t)
+	uploadDummy(t, c, "test.txt")
+	uploadDummy(t, c, "existing.txt")
+	if _, err := c.CreateBucket("archive", storage_go.BucketOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := c.CopyFile("test", "test.txt", "copy.txt")
+	if err != nil {
+		t.Fatalf("CopyFile failed: %v", err)
+	}
+	if resp.Key != "test/copy.txt" || resp.Id == "" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	src, _ := srv.Object("test", "test.txt")
+	if dst, ok := srv.Object("test", "copy.txt"); !ok || string(dst) != string(src) {
+		t.Errorf("unexpected copy %q", dst)
+	}
+
+	if _, err := c.CopyFile("test", "test.txt", "existing.txt"); !errors.Is(err, storage_go.ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+	if _, err := c.CopyFile("test", "test.txt", "existing.txt", storage_go.CopyOptions{Upsert: true}); err != nil {
+		t.Errorf("expected the copy to overwrite the destination: %v", err)
+	}
 
-	fmt.Println(resp, err)
+	contentType := "application/json"
+	resp, err = c.CopyFile("test", "test.txt", "data/test.json", storage_go.CopyOptions{
+		DestinationBucket: "archive",
+		ContentType:       &contentType,
+	})
+	if err != nil || resp.Key != "archive/data/test.json" {
+		t.Fatalf("unexpected response %+v (%v)", resp, err)
+	}
+	body, info, err := c.DownloadFileStream("archive", "data/test.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body.Close()
+	if info.ContentType != contentType {
+		t.Errorf("unexpected content type %q", info.ContentType)
+	}
 }
 
 func TestSignedUrl(t *testing.T) {
-	c := storage_go.NewClient(rawUrl, token, map[string]string{})
+	_, c := newTestServer(t)
+	uploadDummy(t, c, "file_example_MP4_480_1_5MG.mp4")
+
 	resp, err := c.CreateSignedUrl("test", "file_example_MP4_480_1_5MG.mp4", 120)
+	if err != nil {
+		t.Fatalf("CreateSignedUrl failed: %v", err)
+	}
 
-	fmt.Println(resp, err)
+	res, err := http.Get(resp.SignedURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("signed URL returned %s", res.Status)
+	}
 }
 
 func TestPublicUrl(t *testing.T) {
-	c := storage_go.NewClient(rawUrl, token, map[string]string{})
+	srv, c := newTestServer(t)
+	if _, err := c.CreateBucket("shield", storage_go.BucketOptions{Public: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.UploadFile("shield", "book.pdf", strings.NewReader("%PDF")); err != nil {
+		t.Fatal(err)
+	}
+
 	resp := c.GetPublicUrl("shield", "book.pdf")
+	if resp.SignedURL != srv.URL+"/object/public/shield/book.pdf" {
+		t.Errorf("unexpected public URL %q", resp.SignedURL)
+	}
+	res, err := http.Get(resp.SignedURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if string(body) != "%PDF" {
+		t.Errorf("unexpected public content %q", body)
+	}
 
-	fmt.Println(resp)
+	res, err = http.Get(c.GetPublicUrl("test", "book.pdf").SignedURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode == http.StatusOK {
+		t.Errorf("expected a private bucket to refuse public access")
+	}
 }
 
 func TestDeleteFile(t *testing.T) {
-	c := storage_go.NewClient(rawUrl, token, map[string]string{})
-	resp, err := c.RemoveFile("shield", []string{"book.pdf"})
+	srv, c := newTestServer(t)
+	uploadDummy(t, c, "book.pdf")
 
-	fmt.Println(resp, err)
+	resp, err := c.RemoveFile("test", []string{"book.pdf"})
+	if err != nil {
+		t.Fatalf("RemoveFile failed: %v", err)
+	}
+	if len(resp) != 1 {
+		t.Errorf("expected one removed file, got %v", resp)
+	}
+	if _, ok := srv.Object("test", "book.pdf"); ok {
+		t.Errorf("expected the file to be removed")
+	}
 }
 
 func TestListFile(t *testing.T) {
-	c := storage_go.NewClient(rawUrl, token, map[string]string{})
-	resp, err := c.ListFiles("shield", "", storage_go.FileSearchOptions{
+	_, c := newTestServer(t)
+	for _, path := range []string{"b.txt", "a.txt", "folder/c.txt"} {
+		uploadDummy(t, c, path)
+	}
+
+	resp, err := c.ListFiles("test", "", storage_go.FileSearchOptions{
 		Limit:  10,
 		Offset: 0,
 		SortByOptions: storage_go.SortBy{
@@ -75,37 +240,67 @@ func TestListFile(t *testing.T) {
 			Order:  "",
 		},
 	})
-
-	fmt.Println(resp, err)
+	if err != nil {
+		t.Fatalf("ListFiles failed: %v", err)
+	}
+	var names []string
+	for _, file := range resp {
+		names = append(names, file.Name)
+	}
+	if strings.Join(names, ",") != "a.txt,b.txt,folder" {
+		t.Errorf("unexpected listing %v", names)
+	}
+	if resp[2].Id != "" {
+		t.Errorf("expected the folder entry to have no id")
+	}
 }
 
 func TestCreateUploadSignedUrl(t *testing.T) {
-	c := storage_go.NewClient(rawUrl, token, map[string]string{"apiKey": token})
-	resp, err := c.CreateSignedUploadUrl("your-bucket-id", "book.pdf")
-
-	fmt.Println(resp, err)
+	_, c := newTestServer(t)
+	resp, err := c.CreateSignedUploadUrl("test", "book.pdf")
+	if err != nil {
+		t.Fatalf("CreateSignedUploadUrl failed: %v", err)
+	}
+	if !strings.HasPrefix(resp.Url, "/object/upload/sign/test/book.pdf?token=") {
+		t.Errorf("unexpected signed upload URL %q", resp.Url)
+	}
 }
 
 func TestUploadToSignedUrl(t *testing.T) {
-	c := storage_go.NewClient(rawUrl, token, map[string]string{"apiKey": token})
+	srv, c := newTestServer(t)
 	file, err := os.Open("dummy.txt")
 	if err != nil {
-		panic(err)
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	signed, err := c.CreateSignedUploadUrl("test", "vu.txt")
+	if err != nil {
+		t.Fatalf("CreateSignedUploadUrl failed: %v", err)
+	}
+	res, err := c.UploadToSignedUrl(signed.Url, file)
+	if err != nil {
+		t.Fatalf("UploadToSignedUrl failed: %v", err)
+	}
+	if res.Key != "test/vu.txt" {
+		t.Errorf("unexpected key %q", res.Key)
+	}
+	if _, ok := srv.Object("test", "vu.txt"); !ok {
+		t.Errorf("expected the file to be uploaded")
 	}
-	// resp, err := c.CreateSignedUploadUrl("test", "vu.txt")
-	res, err := c.UploadToSignedUrl("your-response-url", file)
-	fmt.Println(res, err)
 }
 
 func TestDownloadFile(t *testing.T) {
-	c := storage_go.NewClient(rawUrl, token, map[string]string{})
+	_, c := newTestServer(t)
+	uploadDummy(t, c, "book.pdf")
+
 	resp, err := c.DownloadFile("test", "book.pdf")
 	if err != nil {
 		t.Fatalf("DownloadFile failed: %v", err)
 	}
 
-	err = os.WriteFile("book.pdf", resp, 0644)
-	if err != nil {
-		t.Fatalf("WriteFile failed: %v", err)
+	want, _ := os.ReadFile("dummy.txt")
+	if string(resp) != string(want) {
+		t.Errorf("unexpected content %q", resp)
 	}
 }
@@ -0,0 +1,70 @@
+package test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	storage_go "github.com/supabase-community/storage-go"
+)
+
+func TestFS(t *testing.T) {
+	_, c := newTestServer(t)
+	uploadTree(t, c, "a.txt", "docs/b.txt", "docs/sub/c.txt", "docs/sub/d.txt", "img/e.png")
+
+	if err := fstest.TestFS(storage_go.NewFS(c, "test", ""), "a.txt", "docs/b.txt", "docs/sub/c.txt", "img/e.png"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fstest.TestFS(storage_go.NewFS(c, "test", "docs"), "b.txt", "sub/c.txt", "sub/d.txt"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFSFileInfo(t *testing.T) {
+	_, c := newTestServer(t)
+	uploadTree(t, c, "docs/sub/c.txt")
+	fsys := storage_go.NewFS(c, "test", "")
+
+	info, err := fs.Stat(fsys, "docs/sub/c.txt")
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if info.IsDir() || info.Size() != int64(len("docs/sub/c.txt")) || info.ModTime().IsZero() {
+		t.Errorf("unexpected file info %v %d %v", info.IsDir(), info.Size(), info.ModTime())
+	}
+	if info, err := fs.Stat(fsys, "docs/sub"); err != nil || !info.IsDir() {
+		t.Errorf("expected a directory, got %v", err)
+	}
+
+	if _, err := fsys.Open("docs/missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist, got %v", err)
+	}
+	if _, err := fs.ReadFile(fsys, "missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist, got %v", err)
+	}
+	if _, err := fsys.Open("/docs"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("expected fs.ErrInvalid, got %v", err)
+	}
+}
+
+func TestFSHTTP(t *testing.T) {
+	_, c := newTestServer(t)
+	uploadTree(t, c, "site/index.html")
+
+	files := httptest.NewServer(http.FileServer(http.FS(storage_go.NewFS(c, "test", "site"))))
+	defer files.Close()
+
+	resp, err := http.Get(files.URL + "/index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "site/index.html" {
+		t.Errorf("unexpected response %d %q", resp.StatusCode, body)
+	}
+}
@@ -0,0 +1,119 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	storage_go "github.com/supabase-community/storage-go"
+)
+
+func TestGetFileInfo(t *testing.T) {
+	_, c := newTestServer(t)
+	contentType := "application/json"
+	cacheControl := "60"
+	if _, err := c.UploadFile("test", "data/info.json", strings.NewReader(`{"a":1}`), storage_go.FileOptions{
+		ContentType:  &contentType,
+		CacheControl: &cacheControl,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := c.GetFileInfo("test", "data/info.json")
+	if err != nil {
+		t.Fatalf("GetFileInfo failed: %v", err)
+	}
+	if info.ContentLength != 7 || info.ContentType != contentType || info.CacheControl != "max-age=60" {
+		t.Errorf("unexpected info %+v", info)
+	}
+	if info.Id == "" || info.Version == "" || info.ETag == "" || info.BucketId != "test" || info.Name != "data/info.json" {
+		t.Errorf("unexpected info %+v", info)
+	}
+	if info.LastModified.IsZero() || info.CreatedAt.IsZero() {
+		t.Errorf("expected the dates to be parsed, got %+v", info)
+	}
+
+	if _, err := c.GetFileInfo("test", "missing.json"); !errors.Is(err, storage_go.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestExists(t *testing.T) {
+	_, c := newTestServer(t)
+	uploadDummy(t, c, "test.txt")
+
+	if exists, err := c.Exists("test", "test.txt"); err != nil || !exists {
+		t.Errorf("expected the file to exist, got %v (%v)", exists, err)
+	}
+	if exists, err := c.Exists("test", "missing.txt"); err != nil || exists {
+		t.Errorf("expected the file to be missing, got %v (%v)", exists, err)
+	}
+}
+
+func TestExistsStatus(t *testing.T) {
+	status := http.StatusBadRequest
+	body := ""
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+	c := storage_go.NewClient(srv.URL, token, map[string]string{})
+	c.SetRetryPolicy(storage_go.NoRetry)
+
+	// An expired or invalid token is answered with a 400 too.
+	body = `{"statusCode":"400","error":"InvalidJWT","message":"jwt expired"}`
+	if exists, err := c.Exists("test", "test.txt"); err == nil || exists {
+		t.Errorf("expected an error for a 400, got %v (%v)", exists, err)
+	}
+
+	body = `{"statusCode":"404","error":"not_found","message":"Object not found"}`
+	if exists, err := c.Exists("test", "missing.txt"); err != nil || exists {
+		t.Errorf("expected a 404 in the body to mean missing, got %v (%v)", exists, err)
+	}
+
+	status = http.StatusForbidden
+	body = ""
+	if _, err := c.Exists("test", "secret.txt"); !errors.Is(err, storage_go.ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized, got %v", err)
+	}
+}
+
+func TestGetFileInfoWithoutInfoRoute(t *testing.T) {
+	var methods []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method+" "+r.URL.Path)
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/object/info/"):
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"statusCode":404,"error":"Not Found","message":"Route GET:` + r.URL.Path + ` not found"}`))
+		case r.URL.Path == "/object/test/missing.txt":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("Content-Length", "5")
+			w.Header().Set("ETag", `"abc"`)
+			w.Header().Set("Last-Modified", "Wed, 21 Oct 2015 07:28:00 GMT")
+		}
+	}))
+	defer srv.Close()
+	c := storage_go.NewClient(srv.URL, token, map[string]string{})
+	c.SetRetryPolicy(storage_go.NoRetry)
+
+	info, err := c.GetFileInfo("test", "file.txt")
+	if err != nil {
+		t.Fatalf("GetFileInfo failed: %v", err)
+	}
+	if info.ContentType != "text/plain" || info.ContentLength != 5 || info.ETag != `"abc"` || info.LastModified.IsZero() || info.Name != "file.txt" || info.BucketId != "test" {
+		t.Errorf("unexpected info %+v", info)
+	}
+	if strings.Join(methods, ",") != "GET /object/info/test/file.txt,HEAD /object/test/file.txt" {
+		t.Errorf("unexpected requests %v", methods)
+	}
+
+	if exists, err := c.Exists("test", "missing.txt"); err != nil || exists {
+		t.Errorf("expected the file to be missing, got %v (%v)", exists, err)
+	}
+}
@@ -0,0 +1,47 @@
+//go:build go1.23
+
+package test
+
+import (
+	"errors"
+	"testing"
+
+	storage_go "github.com/supabase-community/storage-go"
+)
+
+func TestListFilesAll(t *testing.T) {
+	c, _ := newListingClient(t, 15)
+
+	options := storage_go.IterOptions{FileSearchOptions: storage_go.FileSearchOptions{Limit: 4}}
+	count := 0
+	for file, err := range c.ListFilesAll("test", "folder/", options) {
+		if err != nil {
+			t.Fatalf("iteration failed: %v", err)
+		}
+		if file.Name == "" {
+			t.Errorf("unexpected empty file")
+		}
+		count++
+		if count == 10 {
+			break
+		}
+	}
+	if count != 10 {
+		t.Errorf("expected to stop after 10 files, got %d", count)
+	}
+}
+
+func TestListFilesAllError(t *testing.T) {
+	_, c := newTestServer(t)
+
+	errs := 0
+	for _, err := range c.ListFilesAll("missing", "") {
+		if !errors.Is(err, storage_go.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+		errs++
+	}
+	if errs != 1 {
+		t.Errorf("expected a single error, got %d", errs)
+	}
+}
@@ -0,0 +1,148 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	storage_go "github.com/supabase-community/storage-go"
+)
+
+// newListingClient uploads count files to the "test" bucket and returns a
+// client that counts the list requests it sends.
+func newListingClient(t *testing.T, count int) (*storage_go.Client, *int32) {
+	t.Helper()
+	srv, c := newTestServer(t)
+	for i := 0; i < count; i++ {
+		if _, err := c.UploadFile("test", fmt.Sprintf("folder/file-%02d.txt", i), strings.NewReader("data")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var lists int32
+	counting, err := storage_go.NewClientWithOptions(srv.URL, storage_go.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if strings.HasPrefix(req.URL.Path, "/object/list/") {
+				atomic.AddInt32(&lists, 1)
+			}
+			return next.RoundTrip(req)
+		})
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return counting, &lists
+}
+
+func TestListFilesIter(t *testing.T) {
+	for _, prefetch := range []bool{false, true} {
+		t.Run(fmt.Sprintf("prefetch=%v", prefetch), func(t *testing.T) {
+			c, lists := newListingClient(t, 25)
+
+			it := c.ListFilesIter("test", "folder/", storage_go.IterOptions{
+				FileSearchOptions: storage_go.FileSearchOptions{Limit: 10},
+				Prefetch:          prefetch,
+			})
+			defer it.Close()
+			var names []string
+			for it.Next() {
+				names = append(names, it.Object().Name)
+			}
+			if err := it.Err(); err != nil {
+				t.Fatalf("iteration failed: %v", err)
+			}
+			if len(names) != 25 || names[0] != "file-00.txt" || names[24] != "file-24.txt" {
+				t.Errorf("unexpected files %v", names)
+			}
+			if n := atomic.LoadInt32(lists); n != 3 {
+				t.Errorf("expected 3 list requests, got %d", n)
+			}
+		})
+	}
+}
+
+func TestListFilesIterExactPages(t *testing.T) {
+	c, lists := newListingClient(t, 20)
+
+	it := c.ListFilesIter("test", "folder/", storage_go.IterOptions{
+		FileSearchOptions: storage_go.FileSearchOptions{Limit: 10},
+	})
+	count := 0
+	for it.Next() {
+		count++
+	}
+	if it.Err() != nil || count != 20 {
+		t.Errorf("expected 20 files, got %d (%v)", count, it.Err())
+	}
+	// The third, empty page ends the iteration.
+	if n := atomic.LoadInt32(lists); n != 3 {
+		t.Errorf("expected 3 list requests, got %d", n)
+	}
+}
+
+func TestListFilesIterReleasesContext(t *testing.T) {
+	srv, c := newTestServer(t)
+	uploadTree(t, c, "folder/a.txt", "folder/b.txt")
+
+	var ctx context.Context
+	c, err := storage_go.NewClientWithOptions(srv.URL, storage_go.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx = req.Context()
+			return next.RoundTrip(req)
+		})
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	it := c.ListFilesIter("test", "folder/")
+	count := 0
+	for it.Next() {
+		count++
+	}
+	if it.Err() != nil || count != 2 {
+		t.Fatalf("expected 2 files, got %d (%v)", count, it.Err())
+	}
+	if ctx.Err() != context.Canceled {
+		t.Errorf("expected the context to be released after the last page, got %v", ctx.Err())
+	}
+	it.Close()
+	it.Close()
+}
+
+func TestListFilesIterEarlyStop(t *testing.T) {
+	c, lists := newListingClient(t, 25)
+
+	it := c.ListFilesIter("test", "folder/", storage_go.IterOptions{
+		FileSearchOptions: storage_go.FileSearchOptions{Limit: 10},
+	})
+	for i := 0; i < 5 && it.Next(); i++ {
+	}
+	it.Close()
+	if it.Next() {
+		t.Errorf("expected Next to return false after Close")
+	}
+	if it.Err() != nil {
+		t.Errorf("unexpected error %v", it.Err())
+	}
+	if n := atomic.LoadInt32(lists); n != 1 {
+		t.Errorf("expected 1 list request, got %d", n)
+	}
+}
+
+func TestListFilesIterError(t *testing.T) {
+	_, c := newTestServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	it := c.ListFilesIterWithContext(ctx, "test", "")
+	if it.Next() {
+		t.Errorf("expected no files")
+	}
+	if it.Err() != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", it.Err())
+	}
+}
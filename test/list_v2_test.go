@@ -0,0 +1,90 @@
+package test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	storage_go "github.com/supabase-community/storage-go"
+)
+
+func TestListObjectsV2(t *testing.T) {
+	_, c := newTestServer(t)
+	uploadTree(t, c, "a.txt", "docs/b.txt", "docs/sub/c.txt", "img/e.png")
+
+	page, err := c.ListObjectsV2("test", storage_go.ListObjectsV2Request{WithDelimiter: true})
+	if err != nil {
+		t.Fatalf("ListObjectsV2 failed: %v", err)
+	}
+	if page.HasNext || len(page.Objects) != 1 || page.Objects[0].Name != "a.txt" || page.Objects[0].Id == "" {
+		t.Errorf("unexpected objects %+v", page.Objects)
+	}
+	if len(page.Folders) != 2 || page.Folders[0].Name != "docs/" || page.Folders[1].Name != "img/" {
+		t.Errorf("unexpected folders %+v", page.Folders)
+	}
+
+	page, err = c.ListObjectsV2("test", storage_go.ListObjectsV2Request{Prefix: "docs/"})
+	if err != nil {
+		t.Fatalf("ListObjectsV2 failed: %v", err)
+	}
+	if len(page.Folders) != 0 || len(page.Objects) != 2 || page.Objects[1].Name != "docs/sub/c.txt" {
+		t.Errorf("unexpected recursive listing %+v", page)
+	}
+
+	if _, err := c.ListObjectsV2("missing", storage_go.ListObjectsV2Request{}); !errors.Is(err, storage_go.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestListObjectsV2Pages(t *testing.T) {
+	_, c := newTestServer(t)
+	uploadTree(t, c, "a.txt", "b.txt", "c.txt", "d.txt", "e.txt")
+
+	var names []string
+	pages := 0
+	err := c.ListObjectsV2Pages("test", storage_go.ListObjectsV2Request{
+		Limit:  2,
+		SortBy: &storage_go.SortBy{Column: "name", Order: "desc"},
+	}, func(page storage_go.ListObjectsV2Response) bool {
+		pages++
+		for _, object := range page.Objects {
+			names = append(names, object.Name)
+		}
+		return true
+	})
+	if err != nil {
+		t.Fatalf("ListObjectsV2Pages failed: %v", err)
+	}
+	if want := []string{"e.txt", "d.txt", "c.txt", "b.txt", "a.txt"}; !reflect.DeepEqual(names, want) || pages != 3 {
+		t.Errorf("unexpected names %v in %d pages", names, pages)
+	}
+
+	pages = 0
+	_ = c.ListObjectsV2Pages("test", storage_go.ListObjectsV2Request{Limit: 2}, func(page storage_go.ListObjectsV2Response) bool {
+		pages++
+		return false
+	})
+	if pages != 1 {
+		t.Errorf("expected to stop after the first page, got %d", pages)
+	}
+}
+
+func TestListObjectsV2PagesStuckCursor(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"hasNext":true,"nextCursor":"same","folders":[],"objects":[]}`))
+	}))
+	defer srv.Close()
+
+	c := storage_go.NewClient(srv.URL, token, map[string]string{})
+	pages := 0
+	err := c.ListObjectsV2Pages("test", storage_go.ListObjectsV2Request{Cursor: "same"}, func(page storage_go.ListObjectsV2Response) bool {
+		pages++
+		return true
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), "storage: list objects v2: ") || pages != 1 {
+		t.Errorf("expected a storage: list objects v2 error after 1 page, got %d pages and %v", pages, err)
+	}
+}
@@ -0,0 +1,95 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	storage_go "github.com/supabase-community/storage-go"
+)
+
+func TestUploadUserMetadata(t *testing.T) {
+	_, c := newTestServer(t)
+	_, err := c.UploadFile("test", "docs/a.txt", strings.NewReader("a"), storage_go.FileOptions{
+		Metadata: map[string]string{"tenant": "acme", "source": "import"},
+	})
+	if err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+
+	files, err := c.ListFiles("test", "docs", storage_go.FileSearchOptions{})
+	if err != nil || len(files) != 1 {
+		t.Fatalf("unexpected files %+v (%v)", files, err)
+	}
+	if files[0].UserMetadata["tenant"] != "acme" || files[0].UserMetadata["source"] != "import" {
+		t.Errorf("unexpected user metadata %v", files[0].UserMetadata)
+	}
+
+	info, err := c.GetFileInfo("test", "docs/a.txt")
+	if err != nil || info.Metadata["tenant"] != "acme" {
+		t.Errorf("unexpected info metadata %v (%v)", info.Metadata, err)
+	}
+
+	_, err = c.UpdateFile("test", "docs/a.txt", strings.NewReader("b"), storage_go.FileOptions{
+		MetadataJSON: json.RawMessage(`{"tenant":"acme","tags":["a","b"],"version":2}`),
+	})
+	if err != nil {
+		t.Fatalf("UpdateFile failed: %v", err)
+	}
+	info, err = c.GetFileInfo("test", "docs/a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tags, _ := info.Metadata["tags"].([]interface{}); len(tags) != 2 || info.Metadata["version"] != float64(2) {
+		t.Errorf("unexpected info metadata %v", info.Metadata)
+	}
+}
+
+func TestSignedUploadAndCopyUserMetadata(t *testing.T) {
+	_, c := newTestServer(t)
+
+	signed, err := c.CreateSignedUploadUrl("test", "signed.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.UploadToSignedUrl(signed.Url, strings.NewReader("data"), storage_go.FileOptions{
+		Metadata: map[string]string{"tenant": "acme"},
+	}); err != nil {
+		t.Fatalf("UploadToSignedUrl failed: %v", err)
+	}
+	if info, err := c.GetFileInfo("test", "signed.txt"); err != nil || info.Metadata["tenant"] != "acme" {
+		t.Errorf("unexpected metadata %v (%v)", info.Metadata, err)
+	}
+
+	if _, err := c.CopyFile("test", "signed.txt", "kept.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := c.GetFileInfo("test", "kept.txt"); err != nil || info.Metadata["tenant"] != "acme" {
+		t.Errorf("expected the metadata to be copied, got %v (%v)", info.Metadata, err)
+	}
+	if _, err := c.CopyFile("test", "signed.txt", "replaced.txt", storage_go.CopyOptions{
+		Metadata: map[string]string{"tenant": "globex"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := c.GetFileInfo("test", "replaced.txt"); err != nil || info.Metadata["tenant"] != "globex" {
+		t.Errorf("expected the metadata to be replaced, got %v (%v)", info.Metadata, err)
+	}
+}
+
+func TestUploadResumableUserMetadata(t *testing.T) {
+	srv := newTusServer()
+	defer srv.Close()
+
+	c := storage_go.NewClient(srv.URL, token, map[string]string{})
+	_, err := c.UploadResumable("videos", "a.mp4", bytes.NewReader([]byte("data")), storage_go.ResumableUploadOptions{
+		FileOptions: storage_go.FileOptions{Metadata: map[string]string{"tenant": "acme"}},
+	})
+	if err != nil {
+		t.Fatalf("UploadResumable failed: %v", err)
+	}
+	if metadata := srv.uploads["1"].metadata["metadata"]; metadata != `{"tenant":"acme"}` {
+		t.Errorf("unexpected metadata %q", metadata)
+	}
+}
@@ -0,0 +1,102 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	storage_go "github.com/supabase-community/storage-go"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestNewClientWithOptions(t *testing.T) {
+	var mu sync.Mutex
+	var seen http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = r.Header.Clone()
+		mu.Unlock()
+		_, _ = w.Write([]byte("[]"))
+	}))
+	defer srv.Close()
+
+	var calls []string
+	record := func(name string) storage_go.Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" "+req.URL.String())
+				return next.RoundTrip(req)
+			})
+		}
+	}
+	var transportUsed bool
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		transportUsed = true
+		return http.DefaultTransport.RoundTrip(req)
+	})
+
+	c, err := storage_go.NewClientWithOptions(srv.URL,
+		storage_go.WithToken("secret"),
+		storage_go.WithUserAgent("my-service/1.0"),
+		storage_go.WithHeaders(map[string]string{"apikey": "anon"}),
+		storage_go.WithTransport(transport),
+		storage_go.WithMiddleware(record("outer"), record("inner")),
+	)
+	if err != nil {
+		t.Fatalf("NewClientWithOptions failed: %v", err)
+	}
+	if _, err := c.ListBuckets(); err != nil {
+		t.Fatalf("ListBuckets failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if seen.Get("Authorization") != "Bearer secret" || seen.Get("User-Agent") != "my-service/1.0" || seen.Get("apikey") != "anon" {
+		t.Errorf("unexpected headers %v", seen)
+	}
+	if !transportUsed {
+		t.Errorf("expected the custom transport to be used")
+	}
+	if len(calls) != 2 || calls[0] != "outer "+srv.URL+"/bucket" || calls[1] != "inner "+srv.URL+"/bucket" {
+		t.Errorf("unexpected middleware calls %v", calls)
+	}
+}
+
+func TestNewClientWithTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+
+	c, err := storage_go.NewClientWithOptions(srv.URL,
+		storage_go.WithHTTPClient(&http.Client{}),
+		storage_go.WithTimeout(50*time.Millisecond),
+		storage_go.WithRetryPolicy(storage_go.NoRetry),
+	)
+	if err != nil {
+		t.Fatalf("NewClientWithOptions failed: %v", err)
+	}
+	start := time.Now()
+	if _, err := c.ListBuckets(); err == nil {
+		t.Errorf("expected a timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("timeout not applied, call took %v", elapsed)
+	}
+}
+
+func TestNewClientWithOptionsInvalidURL(t *testing.T) {
+	if _, err := storage_go.NewClientWithOptions("://missing-scheme"); err == nil {
+		t.Errorf("expected an error for an invalid URL")
+	}
+}
@@ -0,0 +1,136 @@
+package test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	storage_go "github.com/supabase-community/storage-go"
+)
+
+func newZipArchive(t *testing.T) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, name := range []string{"a.txt", "dir/b.txt"} {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = f.Write(bytes.Repeat([]byte(name), 1000))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestRemoteObjectZipReader(t *testing.T) {
+	archive := newZipArchive(t)
+	var ranged int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/object/archives/data.zip" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Range") != "" {
+			atomic.AddInt32(&ranged, 1)
+		}
+		http.ServeContent(w, r, "data.zip", time.Time{}, bytes.NewReader(archive))
+	}))
+	defer srv.Close()
+
+	c := storage_go.NewClient(srv.URL, token, map[string]string{})
+	object, err := c.OpenRemoteObject("archives", "data.zip", storage_go.RemoteObjectOptions{ReadAhead: 4096})
+	if err != nil {
+		t.Fatalf("OpenRemoteObject failed: %v", err)
+	}
+	if object.Size() != int64(len(archive)) {
+		t.Fatalf("unexpected size %d", object.Size())
+	}
+
+	r, err := zip.NewReader(object, object.Size())
+	if err != nil {
+		t.Fatalf("zip.NewReader failed: %v", err)
+	}
+	if len(r.File) != 2 || r.File[1].Name != "dir/b.txt" {
+		t.Fatalf("unexpected zip entries %v", r.File)
+	}
+	f, err := r.File[1].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(f)
+	f.Close()
+	if err != nil || !bytes.Equal(data, bytes.Repeat([]byte("dir/b.txt"), 1000)) {
+		t.Errorf("unexpected content of dir/b.txt (%v)", err)
+	}
+	if n := atomic.LoadInt32(&ranged); n == 0 || n > 5 {
+		t.Errorf("unexpected number of range requests: %d", n)
+	}
+}
+
+func TestRemoteObjectReadSeeker(t *testing.T) {
+	content := []byte("0123456789abcdefghij")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "data.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	c := storage_go.NewClient(srv.URL, token, map[string]string{})
+	object, err := c.OpenRemoteObject("archives", "data.bin")
+	if err != nil {
+		t.Fatalf("OpenRemoteObject failed: %v", err)
+	}
+
+	if _, err := object.Seek(-5, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(object)
+	if err != nil || string(data) != "fghij" {
+		t.Errorf("unexpected tail %q (%v)", data, err)
+	}
+
+	buf := make([]byte, 4)
+	if n, err := object.ReadAt(buf, 10); n != 4 || err != nil || string(buf) != "abcd" {
+		t.Errorf("ReadAt returned %d %q %v", n, buf, err)
+	}
+	if n, err := object.ReadAt(buf, 18); n != 2 || err != io.EOF {
+		t.Errorf("ReadAt past the end returned %d %v", n, err)
+	}
+}
+
+func TestRemoteObjectContext(t *testing.T) {
+	content := []byte("0123456789")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "data.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	c := storage_go.NewClient(srv.URL, token, map[string]string{})
+	ctx, cancel := context.WithCancel(context.Background())
+	object, err := c.OpenRemoteObjectWithContext(ctx, "archives", "data.bin")
+	if err != nil {
+		t.Fatalf("OpenRemoteObjectWithContext failed: %v", err)
+	}
+	cancel()
+
+	// The context of OpenRemoteObjectWithContext only applies to the HEAD request.
+	buf := make([]byte, 4)
+	if n, err := object.ReadAt(buf, 2); n != 4 || err != nil || string(buf) != "2345" {
+		t.Errorf("ReadAt returned %d %q %v", n, buf, err)
+	}
+	if _, err := object.ReadAtWithContext(ctx, buf, 2); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected ReadAtWithContext to carry its context, got %v", err)
+	}
+	if _, err := object.ReadAt(buf, -1); err == nil || !strings.HasPrefix(err.Error(), "storage: remote object: ") {
+		t.Errorf("expected a storage: remote object error, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	storage_go "github.com/supabase-community/storage-go"
+)
+
+func TestGetResponsiveImageWidths(t *testing.T) {
+	srv, c := newTestServer(t)
+
+	image, err := c.GetResponsiveImage("test", "products/shoe.png", storage_go.ResponsiveImageOptions{
+		Widths:    []int{800, 400},
+		Transform: storage_go.TransformOptions{Width: 400, Height: 300, Resize: storage_go.ResizeCover},
+		Formats:   []storage_go.ImageFormat{storage_go.FormatWebp, storage_go.FormatOrigin},
+		Sizes:     "(max-width: 600px) 100vw, 50vw",
+		Alt:       `Red "running" shoe`,
+	})
+	if err != nil {
+		t.Fatalf("GetResponsiveImage failed: %v", err)
+	}
+
+	base := srv.URL + "/render/image/public/test/products/shoe.png?"
+	if len(image.Sources) != 2 {
+		t.Fatalf("expected 2 sources, got %+v", image.Sources)
+	}
+	webp := image.Sources[0]
+	if webp.Format != storage_go.FormatWebp || webp.Type != "image/webp" {
+		t.Errorf("unexpected first source %+v", webp)
+	}
+	wantSrcset := base + "format=webp&height=300&resize=cover&width=400 400w, " +
+		base + "format=webp&height=600&resize=cover&width=800 800w"
+	if webp.Srcset() != wantSrcset {
+		t.Errorf("unexpected srcset\n got %s\nwant %s", webp.Srcset(), wantSrcset)
+	}
+	if fallback := image.Sources[1]; fallback.Type != "" || fallback.Entries[1].Width != 800 {
+		t.Errorf("unexpected fallback source %+v", fallback)
+	}
+	if image.Src() != base+"format=origin&height=600&resize=cover&width=800" {
+		t.Errorf("unexpected src %s", image.Src())
+	}
+
+	html := image.HTML()
+	for _, want := range []string{
+		`<picture><source type="image/webp" srcset="` + strings.ReplaceAll(wantSrcset, "&", "&amp;") + `" sizes="(max-width: 600px) 100vw, 50vw">`,
+		`<img src="` + strings.ReplaceAll(image.Src(), "&", "&amp;") + `" srcset="`,
+		`alt="Red &#34;running&#34; shoe"></picture>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected %s in %s", want, html)
+		}
+	}
+	if strings.Count(html, "<source") != 1 {
+		t.Errorf("expected a single source in %s", html)
+	}
+}
+
+func TestGetResponsiveImageDensities(t *testing.T) {
+	srv, c := newTestServer(t)
+
+	image, err := c.GetResponsiveImage("test", "logo.png", storage_go.ResponsiveImageOptions{
+		Densities: []float64{2, 1, 1.5},
+		Transform: storage_go.TransformOptions{Width: 100},
+	})
+	if err != nil {
+		t.Fatalf("GetResponsiveImage failed: %v", err)
+	}
+
+	base := srv.URL + "/render/image/public/test/logo.png?"
+	want := base + "width=100 1x, " + base + "width=150 1.5x, " + base + "width=200 2x"
+	if len(image.Sources) != 1 || image.Sources[0].Srcset() != want {
+		t.Errorf("unexpected sources %+v", image.Sources)
+	}
+	if html := image.HTML(); strings.Contains(html, "<source") || strings.Contains(html, "sizes=") || !strings.HasSuffix(html, ` alt=""></picture>`) {
+		t.Errorf("unexpected HTML %s", html)
+	}
+}
+
+func TestGetResponsiveImageInvalid(t *testing.T) {
+	_, c := newTestServer(t)
+
+	invalid := map[string]storage_go.ResponsiveImageOptions{
+		"no sizes":             {},
+		"widths and densities": {Widths: []int{100}, Densities: []float64{1}, Transform: storage_go.TransformOptions{Width: 100}},
+		"densities no width":   {Densities: []float64{1}},
+		"negative density":     {Densities: []float64{-1}, Transform: storage_go.TransformOptions{Width: 100}},
+	}
+	for name, options := range invalid {
+		if _, err := c.GetResponsiveImage("test", "img.png", options); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	_, err := c.GetResponsiveImage("test", "img.png", storage_go.ResponsiveImageOptions{
+		Densities: []float64{1, 2, 3},
+		Transform: storage_go.TransformOptions{Width: 1000},
+	})
+	if !errors.Is(err, storage_go.ErrInvalidTransform) {
+		t.Errorf("expected ErrInvalidTransform for a 3000 pixels wide image, got %v", err)
+	}
+}
+
+func TestCreateSignedResponsiveImage(t *testing.T) {
+	srv, c := newTestServer(t)
+	uploadTree(t, c, "img.png")
+	c, requests := newRecordingClient(t, srv)
+
+	image, err := c.CreateSignedResponsiveImage("test", "img.png", 60, storage_go.ResponsiveImageOptions{
+		Widths:  []int{200, 400},
+		Formats: []storage_go.ImageFormat{storage_go.FormatAvif, storage_go.FormatWebp},
+	})
+	if err != nil {
+		t.Fatalf("CreateSignedResponsiveImage failed: %v", err)
+	}
+	if sent := requests(); len(sent) != 4 || !strings.Contains(sent[0], `"transform":{"width":200,"format":"avif"}`) {
+		t.Errorf("unexpected requests %v", sent)
+	}
+
+	for _, source := range image.Sources {
+		for _, entry := range source.Entries {
+			if !strings.HasPrefix(entry.URL, srv.URL+"/render/image/sign/test/img.png?token=") {
+				t.Errorf("unexpected signed URL %s", entry.URL)
+				continue
+			}
+			resp, err := http.Get(entry.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK || string(data) != "img.png" {
+				t.Errorf("GET %s: %d %q", entry.URL, resp.StatusCode, data)
+			}
+		}
+	}
+
+	if _, err := c.CreateSignedResponsiveImage("test", "missing.png", 60, storage_go.ResponsiveImageOptions{Widths: []int{200}}); !errors.Is(err, storage_go.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
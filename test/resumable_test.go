@@ -0,0 +1,275 @@
+package test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	storage_go "github.com/supabase-community/storage-go"
+)
+
+// tusServer is a minimal stand-in for the Storage TUS endpoint.
+type tusServer struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	uploads     map[string]*tusUpload
+	nextID      int
+	failPatchAt int  // fail the n-th PATCH request (1-based), 0 disables
+	dropPatchAt int  // apply the n-th PATCH request but close the connection instead of answering
+	conflict    bool // reject every PATCH request with a conflict
+	patches     int
+	offsets     []int64
+}
+
+type tusUpload struct {
+	length   int64
+	metadata map[string]string
+	data     bytes.Buffer
+}
+
+func newTusServer() *tusServer {
+	s := &tusServer{uploads: map[string]*tusUpload{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+func (s *tusServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.Header.Get("Tus-Resumable") != "1.0.0" {
+		http.Error(w, "missing Tus-Resumable", http.StatusPreconditionFailed)
+		return
+	}
+
+	if r.Method == http.MethodPost && r.URL.Path == "/upload/resumable" {
+		length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid Upload-Length", http.StatusBadRequest)
+			return
+		}
+		metadata := map[string]string{}
+		for _, pair := range strings.Split(r.Header.Get("Upload-Metadata"), ",") {
+			parts := strings.SplitN(pair, " ", 2)
+			value, _ := base64.StdEncoding.DecodeString(parts[1])
+			metadata[parts[0]] = string(value)
+		}
+		s.nextID++
+		id := strconv.Itoa(s.nextID)
+		s.uploads[id] = &tusUpload{length: length, metadata: metadata}
+		w.Header().Set("Location", s.URL+"/upload/resumable/"+id)
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+
+	upload, ok := s.uploads[strings.TrimPrefix(r.URL.Path, "/upload/resumable/")]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodHead:
+		w.Header().Set("Upload-Offset", strconv.Itoa(upload.data.Len()))
+		w.Header().Set("Upload-Length", strconv.FormatInt(upload.length, 10))
+		w.WriteHeader(http.StatusOK)
+	case http.MethodPatch:
+		s.patches++
+		offset, _ := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+		s.offsets = append(s.offsets, offset)
+		if s.patches == s.failPatchAt {
+			http.Error(w, "gateway unavailable", http.StatusBadGateway)
+			return
+		}
+		if offset != int64(upload.data.Len()) || s.conflict {
+			http.Error(w, "offset mismatch", http.StatusConflict)
+			return
+		}
+		_, _ = io.Copy(&upload.data, r.Body)
+		if s.patches == s.dropPatchAt {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		w.Header().Set("Upload-Offset", strconv.Itoa(upload.data.Len()))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestUploadResumable(t *testing.T) {
+	srv := newTusServer()
+	defer srv.Close()
+
+	content := bytes.Repeat([]byte("0123456789"), 25)
+	var progress []int64
+	c := storage_go.NewClient(srv.URL, token, map[string]string{})
+	contentType := "video/mp4"
+	resp, err := c.UploadResumable("videos", "clips/a.mp4", bytes.NewReader(content), storage_go.ResumableUploadOptions{
+		FileOptions: storage_go.FileOptions{ContentType: &contentType},
+		ChunkSize:   64,
+		OnProgress: func(uploaded, total int64) {
+			progress = append(progress, uploaded)
+		},
+	})
+	if err != nil {
+		t.Fatalf("UploadResumable failed: %v", err)
+	}
+	if resp.Key != "videos/clips/a.mp4" {
+		t.Errorf("unexpected key %q", resp.Key)
+	}
+
+	upload := srv.uploads["1"]
+	if !bytes.Equal(upload.data.Bytes(), content) {
+		t.Errorf("uploaded content does not match")
+	}
+	if upload.metadata["bucketName"] != "videos" || upload.metadata["objectName"] != "clips/a.mp4" || upload.metadata["contentType"] != contentType {
+		t.Errorf("unexpected metadata %v", upload.metadata)
+	}
+	if fmt.Sprint(progress) != "[0 64 128 192 250]" {
+		t.Errorf("unexpected progress %v", progress)
+	}
+}
+
+func TestUploadResumableResumesAfterFailure(t *testing.T) {
+	srv := newTusServer()
+	defer srv.Close()
+	srv.failPatchAt = 3
+
+	content := bytes.Repeat([]byte("abcdefghij"), 30)
+	storePath := filepath.Join(t.TempDir(), "uploads.json")
+	store := storage_go.NewFileFingerprintStore(storePath)
+	options := storage_go.ResumableUploadOptions{ChunkSize: 100, Store: store}
+	c := storage_go.NewClient(srv.URL, token, map[string]string{})
+	// Let the failed chunk end the first attempt instead of being retried.
+	c.SetRetryPolicy(storage_go.NoRetry)
+
+	if _, err := c.UploadResumable("videos", "b.mp4", bytes.NewReader(content), options); err == nil {
+		t.Fatalf("expected the first attempt to fail")
+	}
+	if _, ok, _ := store.Get("videos/b.mp4:300"); !ok {
+		t.Fatalf("expected the unfinished upload to be recorded")
+	}
+
+	// A fresh store on the same file simulates a restarted process.
+	options.Store = storage_go.NewFileFingerprintStore(storePath)
+	if _, err := c.UploadResumable("videos", "b.mp4", bytes.NewReader(content), options); err != nil {
+		t.Fatalf("resumed upload failed: %v", err)
+	}
+
+	if len(srv.uploads) != 1 {
+		t.Errorf("expected the upload to be resumed, got %d uploads", len(srv.uploads))
+	}
+	if !bytes.Equal(srv.uploads["1"].data.Bytes(), content) {
+		t.Errorf("uploaded content does not match")
+	}
+	if fmt.Sprint(srv.offsets) != "[0 100 200 200]" {
+		t.Errorf("unexpected PATCH offsets %v", srv.offsets)
+	}
+	if _, ok, _ := store.Get("videos/b.mp4:300"); ok {
+		t.Errorf("expected the finished upload to be removed from the store")
+	}
+}
+
+func TestUploadResumableResyncsLostResponse(t *testing.T) {
+	for name, policy := range map[string]storage_go.RetryPolicy{"retried": fastRetryPolicy, "not retried": storage_go.NoRetry} {
+		t.Run(name, func(t *testing.T) {
+			srv := newTusServer()
+			defer srv.Close()
+			srv.dropPatchAt = 2
+
+			content := bytes.Repeat([]byte("abcdefghij"), 30)
+			c := storage_go.NewClient(srv.URL, token, map[string]string{})
+			c.SetRetryPolicy(policy)
+			var progress []int64
+			_, err := c.UploadResumable("videos", "c.mp4", bytes.NewReader(content), storage_go.ResumableUploadOptions{
+				ChunkSize: 100,
+				OnProgress: func(uploaded, total int64) {
+					progress = append(progress, uploaded)
+				},
+			})
+			if err != nil {
+				t.Fatalf("UploadResumable failed: %v", err)
+			}
+			if !bytes.Equal(srv.uploads["1"].data.Bytes(), content) {
+				t.Errorf("uploaded content does not match")
+			}
+			if fmt.Sprint(progress) != "[0 100 200 300]" {
+				t.Errorf("unexpected progress %v", progress)
+			}
+		})
+	}
+}
+
+func TestUploadResumableConflictPersists(t *testing.T) {
+	srv := newTusServer()
+	defer srv.Close()
+	srv.conflict = true
+
+	c := storage_go.NewClient(srv.URL, token, map[string]string{})
+	c.SetRetryPolicy(storage_go.NoRetry)
+	_, err := c.UploadResumable("videos", "d.mp4", bytes.NewReader(make([]byte, 300)), storage_go.ResumableUploadOptions{ChunkSize: 100})
+	if !errors.Is(err, storage_go.ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+	// The chunk is sent again once after reading the offset back from the server.
+	if fmt.Sprint(srv.offsets) != "[0 0]" {
+		t.Errorf("unexpected PATCH offsets %v", srv.offsets)
+	}
+}
+
+func TestUploadResumableWithoutLocation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	c := storage_go.NewClient(srv.URL, token, map[string]string{})
+	_, err := c.UploadResumable("videos", "e.mp4", bytes.NewReader([]byte("data")))
+	if err == nil || !strings.HasPrefix(err.Error(), "storage: resumable upload: ") {
+		t.Errorf("expected a storage: resumable upload error, got %v", err)
+	}
+}
+
+func TestUploadResumableFromCurrentPosition(t *testing.T) {
+	srv := newTusServer()
+	defer srv.Close()
+	// The response to the second chunk is lost, so the rest is sent after
+	// reading the offset back from the server.
+	srv.dropPatchAt = 2
+
+	data := bytes.NewReader([]byte("0123456789"))
+	if _, err := data.Seek(3, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	c := storage_go.NewClient(srv.URL, token, map[string]string{})
+	c.SetRetryPolicy(storage_go.NoRetry)
+	var progress []int64
+	_, err := c.UploadResumable("videos", "f.mp4", data, storage_go.ResumableUploadOptions{
+		ChunkSize: 3,
+		OnProgress: func(uploaded, total int64) {
+			progress = append(progress, uploaded, total)
+		},
+	})
+	if err != nil {
+		t.Fatalf("UploadResumable failed: %v", err)
+	}
+	upload := srv.uploads["1"]
+	if upload.length != 7 || upload.data.String() != "3456789" {
+		t.Errorf("expected the bytes after the current position, got %d bytes %q", upload.length, upload.data.String())
+	}
+	if fmt.Sprint(progress) != "[0 7 3 7 6 7 7 7]" {
+		t.Errorf("unexpected progress %v", progress)
+	}
+}
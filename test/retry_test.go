@@ -0,0 +1,269 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	storage_go "github.com/supabase-community/storage-go"
+)
+
+var fastRetryPolicy = storage_go.RetryPolicy{
+	MaxAttempts:          3,
+	InitialBackoff:       time.Millisecond,
+	MaxBackoff:           10 * time.Millisecond,
+	RetryableStatusCodes: []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable},
+}
+
+// flakyServer fails the first failures requests with status and records every attempt.
+type flakyServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	failures int
+	status   int
+	header   http.Header
+	bodies   []string
+	times    []time.Time
+}
+
+func newFlakyServer(failures int, status int) *flakyServer {
+	s := &flakyServer{failures: failures, status: status, header: http.Header{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		body, _ := io.ReadAll(r.Body)
+		s.bodies = append(s.bodies, string(body))
+		s.times = append(s.times, time.Now())
+		if len(s.bodies) <= s.failures {
+			for name, values := range s.header {
+				w.Header()[name] = values
+			}
+			w.WriteHeader(s.status)
+			return
+		}
+		switch {
+		case r.URL.Path == "/bucket":
+			_, _ = w.Write([]byte(`[{"id":"test"}]`))
+		default:
+			_, _ = w.Write([]byte(`{"Key":"test/file.txt","message":"ok"}`))
+		}
+	}))
+	return s
+}
+
+func (s *flakyServer) attempts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.bodies)
+}
+
+func TestRetryIdempotentRequest(t *testing.T) {
+	srv := newFlakyServer(2, http.StatusServiceUnavailable)
+	defer srv.Close()
+
+	c := storage_go.NewClient(srv.URL, token, map[string]string{})
+	c.SetRetryPolicy(fastRetryPolicy)
+	buckets, err := c.ListBuckets()
+	if err != nil {
+		t.Fatalf("ListBuckets failed: %v", err)
+	}
+	if len(buckets) != 1 || srv.attempts() != 3 {
+		t.Errorf("unexpected result %v after %d attempts", buckets, srv.attempts())
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	srv := newFlakyServer(5, http.StatusBadGateway)
+	defer srv.Close()
+
+	c := storage_go.NewClient(srv.URL, token, map[string]string{})
+	c.SetRetryPolicy(fastRetryPolicy)
+	if _, err := c.ListBuckets(); err == nil {
+		t.Errorf("expected an error")
+	}
+	if srv.attempts() != 3 {
+		t.Errorf("expected 3 attempts, got %d", srv.attempts())
+	}
+}
+
+func TestRetryHonorsRetryAfter(t *testing.T) {
+	srv := newFlakyServer(1, http.StatusTooManyRequests)
+	srv.header.Set("Retry-After", "1")
+	defer srv.Close()
+
+	c := storage_go.NewClient(srv.URL, token, map[string]string{})
+	c.SetRetryPolicy(fastRetryPolicy)
+	if _, err := c.ListBuckets(); err != nil {
+		t.Fatalf("ListBuckets failed: %v", err)
+	}
+	if delay := srv.times[1].Sub(srv.times[0]); delay < time.Second {
+		t.Errorf("expected to wait for Retry-After, waited %v", delay)
+	}
+}
+
+func TestRetryAfterLongerThanMaxBackoff(t *testing.T) {
+	srv := newFlakyServer(1, http.StatusTooManyRequests)
+	srv.header.Set("Retry-After", strconv.Itoa(int(storage_go.DefaultRetryPolicy.MaxBackoff/time.Second)+1))
+	defer srv.Close()
+
+	// The default policy waits for the Retry-After delay; the context ends the
+	// wait early so that the test does not have to sit through it.
+	c := storage_go.NewClient(srv.URL, token, map[string]string{})
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if _, err := c.ListBucketsWithContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the client to wait for Retry-After, got %v", err)
+	}
+	if srv.attempts() != 1 {
+		t.Errorf("expected a single attempt before the deadline, got %d", srv.attempts())
+	}
+}
+
+func TestRetryAfterTooLong(t *testing.T) {
+	srv := newFlakyServer(1, http.StatusServiceUnavailable)
+	srv.header.Set("Retry-After", "86400")
+	defer srv.Close()
+
+	c := storage_go.NewClient(srv.URL, token, map[string]string{})
+	c.SetRetryPolicy(fastRetryPolicy)
+	start := time.Now()
+	_, err := c.ListBuckets()
+	var storageErr *storage_go.StorageError
+	if !errors.As(err, &storageErr) || storageErr.Status != http.StatusServiceUnavailable {
+		t.Errorf("expected the 503 to be returned, got %v", err)
+	}
+	if srv.attempts() != 1 || time.Since(start) > time.Second {
+		t.Errorf("expected a single attempt without waiting, got %d in %v", srv.attempts(), time.Since(start))
+	}
+}
+
+func TestRetryRewindsUploadBody(t *testing.T) {
+	srv := newFlakyServer(1, http.StatusBadGateway)
+	defer srv.Close()
+
+	c := storage_go.NewClient(srv.URL, token, map[string]string{})
+	c.SetRetryPolicy(fastRetryPolicy)
+	if _, err := c.UploadFile("test", "file.txt", strings.NewReader("file content")); err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+	if srv.attempts() != 2 || srv.bodies[1] != "file content" {
+		t.Errorf("unexpected attempts %q", srv.bodies)
+	}
+}
+
+func TestRetrySkipsUnsafeRequests(t *testing.T) {
+	c := func(srv *flakyServer) *storage_go.Client {
+		c := storage_go.NewClient(srv.URL, token, map[string]string{})
+		c.SetRetryPolicy(fastRetryPolicy)
+		return c
+	}
+
+	// A move is not idempotent.
+	moveSrv := newFlakyServer(1, http.StatusBadGateway)
+	defer moveSrv.Close()
+	if _, err := c(moveSrv).MoveFile("test", "a.txt", "b.txt"); err == nil || moveSrv.attempts() != 1 {
+		t.Errorf("expected a single failed move, got %d attempts (%v)", moveSrv.attempts(), err)
+	}
+
+	// A body that cannot be rewound is sent once.
+	streamSrv := newFlakyServer(1, http.StatusBadGateway)
+	defer streamSrv.Close()
+	body := io.MultiReader(strings.NewReader("streamed"))
+	if _, err := c(streamSrv).UploadFile("test", "file.txt", body); err == nil || streamSrv.attempts() != 1 {
+		t.Errorf("expected a single failed upload, got %d attempts (%v)", streamSrv.attempts(), err)
+	}
+}
+
+func TestRetryPolicyPerCall(t *testing.T) {
+	srv := newFlakyServer(1, http.StatusServiceUnavailable)
+	defer srv.Close()
+
+	c := storage_go.NewClient(srv.URL, token, map[string]string{})
+	c.SetRetryPolicy(fastRetryPolicy)
+	ctx := storage_go.ContextWithRetryPolicy(context.Background(), storage_go.NoRetry)
+	if _, err := c.ListBucketsWithContext(ctx); err == nil {
+		t.Errorf("expected the call to fail without retries")
+	}
+	if srv.attempts() != 1 {
+		t.Errorf("expected 1 attempt, got %d", srv.attempts())
+	}
+}
+
+// seekOnlyReader hides the io.ReaderAt of a bytes.Reader, so an upload can
+// only rewind it by seeking.
+type seekOnlyReader struct {
+	r *bytes.Reader
+}
+
+func (s *seekOnlyReader) Read(p []byte) (int, error) { return s.r.Read(p) }
+func (s *seekOnlyReader) Seek(offset int64, whence int) (int64, error) {
+	return s.r.Seek(offset, whence)
+}
+
+// readerAtFile is an io.ReadSeeker and io.ReaderAt, like an *os.File.
+type readerAtFile struct {
+	*bytes.Reader
+}
+
+func TestRetryUploadBodyAnsweredEarly(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789abcdef"), 1<<12)
+	bodies := map[string]func() io.Reader{
+		"seeker":   func() io.Reader { return &seekOnlyReader{r: bytes.NewReader(content)} },
+		"readerAt": func() io.Reader { return readerAtFile{bytes.NewReader(content)} },
+	}
+	for name, body := range bodies {
+		t.Run(name, func(t *testing.T) {
+			srv := newFlakyServer(0, http.StatusOK)
+			defer srv.Close()
+
+			// The first attempt is answered with a 503 before its body is read,
+			// and the body is still read and closed afterwards, like the write
+			// goroutine of net/http does.
+			var once sync.Once
+			var written sync.WaitGroup
+			earlyAnswer := func(next http.RoundTripper) http.RoundTripper {
+				return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					first := false
+					once.Do(func() { first = true })
+					if !first {
+						return next.RoundTrip(req)
+					}
+					written.Add(1)
+					go func() {
+						defer written.Done()
+						time.Sleep(10 * time.Millisecond)
+						_, _ = io.Copy(io.Discard, req.Body)
+						req.Body.Close()
+					}()
+					return &http.Response{
+						StatusCode: http.StatusServiceUnavailable,
+						Header:     http.Header{},
+						Body:       io.NopCloser(strings.NewReader("")),
+						Request:    req,
+					}, nil
+				})
+			}
+			defer written.Wait()
+
+			c, err := storage_go.NewClientWithOptions(srv.URL, storage_go.WithMiddleware(earlyAnswer), storage_go.WithRetryPolicy(fastRetryPolicy))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := c.UploadFile("test", "file.txt", body()); err != nil {
+				t.Fatalf("UploadFile failed: %v", err)
+			}
+			if srv.attempts() != 1 || srv.bodies[0] != string(content) {
+				t.Errorf("expected the full content in a single attempt on the server, got %d attempts", srv.attempts())
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	storage_go "github.com/supabase-community/storage-go"
+)
+
+func TestCreateSignedUrls(t *testing.T) {
+	srv, c := newTestServer(t)
+	var paths []string
+	for i := 0; i < 7; i++ {
+		paths = append(paths, fmt.Sprintf("gallery/%d.png", i))
+	}
+	uploadTree(t, c, paths...)
+	paths = append(paths[:3], append([]string{"gallery/missing.png"}, paths[3:]...)...)
+
+	var requests int32
+	counting, err := storage_go.NewClientWithOptions(srv.URL, storage_go.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			atomic.AddInt32(&requests, 1)
+			return next.RoundTrip(req)
+		})
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := counting.CreateSignedUrls("test", paths, 60, storage_go.SignedUrlsOptions{BatchSize: 3})
+	if err != nil {
+		t.Fatalf("CreateSignedUrls failed: %v", err)
+	}
+	if len(results) != len(paths) {
+		t.Fatalf("expected %d results, got %d", len(paths), len(results))
+	}
+	if n := atomic.LoadInt32(&requests); n != 3 {
+		t.Errorf("expected 3 requests, got %d", n)
+	}
+
+	for i, result := range results {
+		if result.Path != paths[i] {
+			t.Errorf("result %d is for %q, expected %q", i, result.Path, paths[i])
+		}
+		if result.Path == "gallery/missing.png" {
+			if result.Error == "" || result.SignedURL != "" {
+				t.Errorf("expected an error for the missing file, got %+v", result)
+			}
+			continue
+		}
+		if result.Error != "" {
+			t.Errorf("unexpected error for %s: %s", result.Path, result.Error)
+			continue
+		}
+		resp, err := http.Get(result.SignedURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || string(body) != result.Path {
+			t.Errorf("unexpected download of %s: %d %q", result.Path, resp.StatusCode, body)
+		}
+	}
+}
+
+func TestCreateSignedUrlsEmpty(t *testing.T) {
+	_, c := newTestServer(t)
+	results, err := c.CreateSignedUrls("test", nil, 60)
+	if err != nil || len(results) != 0 {
+		t.Errorf("expected no results, got %v (%v)", results, err)
+	}
+}
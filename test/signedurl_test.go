@@ -0,0 +1,149 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	storage_go "github.com/supabase-community/storage-go"
+	"github.com/supabase-community/storage-go/signedurl"
+)
+
+func TestSignedUrlVerify(t *testing.T) {
+	signer := storage_go.NewLocalSigner(jwtSecret)
+	verifier := signedurl.New(jwtSecret)
+
+	resp, err := signer.CreateSignedUrl("test", "folder/my photo.png", 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := verifier.Verify(resp.SignedURL)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if claims.URL != "test/folder/my photo.png" {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+
+	transformed, err := signer.CreateSignedUrl("test", "img.png", 60, storage_go.UrlOptions{
+		Transform: &storage_go.TransformOptions{Width: 100},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifier.Verify(transformed.SignedURL); err != nil {
+		t.Errorf("Verify failed for a transformation: %v", err)
+	}
+
+	mismatches := []string{
+		strings.Replace(resp.SignedURL, "my%20photo.png", "other.png", 1),
+		strings.Replace(transformed.SignedURL, "/render/image/sign/", "/object/sign/", 1),
+	}
+	for _, signedURL := range mismatches {
+		if _, err := verifier.Verify(signedURL); !errors.Is(err, signedurl.ErrObjectMismatch) {
+			t.Errorf("%s: expected ErrObjectMismatch, got %v", signedURL, err)
+		}
+	}
+	if _, err := signedurl.New("other-secret").Verify(resp.SignedURL); !errors.Is(err, storage_go.ErrInvalidToken) {
+		t.Errorf("expected ErrInvalidToken, got %v", err)
+	}
+	if _, err := verifier.Verify(strings.Split(resp.SignedURL, "?")[0]); !errors.Is(err, signedurl.ErrMissingToken) {
+		t.Errorf("expected ErrMissingToken, got %v", err)
+	}
+	if _, err := verifier.Verify("https://example.com/test/img.png?token=abc"); err == nil {
+		t.Error("expected an error for a URL that is not signed")
+	}
+}
+
+func TestSignedUrlMiddleware(t *testing.T) {
+	signer := storage_go.NewLocalSigner(jwtSecret)
+
+	downloads := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := signedurl.ClaimsFromContext(r.Context())
+		if !ok {
+			t.Error("expected claims in the context")
+		}
+		io.WriteString(w, claims.URL)
+	})
+	mux := http.NewServeMux()
+	mux.Handle("/downloads/", signedurl.New(jwtSecret).Middleware(signedurl.Options{Prefix: "/downloads/"})(downloads))
+	proxy := httptest.NewServer(mux)
+	defer proxy.Close()
+
+	token := func(object string, expiresAt int64) string {
+		return mustSignClaims(t, signer, storage_go.SignedUrlClaims{URL: object, IssuedAt: time.Now().Unix(), ExpiresAt: expiresAt})
+	}
+	later := time.Now().Add(time.Minute).Unix()
+	tests := []struct {
+		path    string
+		status  int
+		body    string
+		message string
+	}{
+		{path: "/downloads/test/a%20b.png?token=" + token("test/a b.png", later), status: http.StatusOK, body: "test/a b.png"},
+		{path: "/downloads/test/other.png?token=" + token("test/a b.png", later), status: http.StatusBadRequest, message: signedurl.ErrObjectMismatch.Error()},
+		{path: "/downloads/test/a.png?token=" + token("test/a.png", time.Now().Unix()-1), status: http.StatusBadRequest, message: "jwt expired"},
+		{path: "/downloads/test/a.png?token=abc.def.ghi", status: http.StatusBadRequest, message: "invalid signature"},
+		{path: "/downloads/test/a.png", status: http.StatusBadRequest, message: signedurl.ErrMissingToken.Error()},
+	}
+	for _, test := range tests {
+		resp, err := http.Get(proxy.URL + test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != test.status {
+			t.Errorf("GET %s: expected %d, got %d %s", test.path, test.status, resp.StatusCode, data)
+			continue
+		}
+		if test.status == http.StatusOK {
+			if string(data) != test.body {
+				t.Errorf("GET %s: unexpected body %s", test.path, data)
+			}
+			continue
+		}
+		var body struct {
+			Error   string `json:"error"`
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(data, &body); err != nil || body.Error != "InvalidJWT" || body.Message != test.message {
+			t.Errorf("GET %s: unexpected error body %s", test.path, data)
+		}
+	}
+}
+
+func TestSignedUrlMiddlewareOptions(t *testing.T) {
+	signer := storage_go.NewLocalSigner(jwtSecret)
+
+	var rejected error
+	middleware := signedurl.New(jwtSecret).Middleware(signedurl.Options{
+		Object: func(r *http.Request) (string, string, bool) {
+			return "test", r.URL.Query().Get("file"), true
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			rejected = err
+			w.WriteHeader(http.StatusForbidden)
+		},
+	})
+	handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	now := time.Now().Unix()
+	valid := mustSignClaims(t, signer, storage_go.SignedUrlClaims{URL: "test/a.png", IssuedAt: now, ExpiresAt: now + 60})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/download?file=a.png&token="+valid, nil))
+	if rec.Code != http.StatusOK || rejected != nil {
+		t.Errorf("expected the request to pass, got %d, %v", rec.Code, rejected)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/download?file=b.png&token="+valid, nil))
+	if rec.Code != http.StatusForbidden || !errors.Is(rejected, signedurl.ErrObjectMismatch) {
+		t.Errorf("expected the request to be rejected, got %d, %v", rec.Code, rejected)
+	}
+}
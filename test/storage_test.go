@@ -1,32 +1,52 @@
 package test
 
 import (
-	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/supabase-community/storage-go"
 )
 
 func TestBucketListAll(t *testing.T) {
-	c := storage_go.NewClient(rawUrl, token, map[string]string{})
+	_, c := newTestServer(t)
 	resp, err := c.ListBuckets()
-	fmt.Println(resp, err)
+	if err != nil {
+		t.Fatalf("ListBuckets failed: %v", err)
+	}
+	if len(resp) != 1 || resp[0].Id != "test" {
+		t.Errorf("unexpected buckets %v", resp)
+	}
 }
 
 func TestBucketFetchById(t *testing.T) {
-	c := storage_go.NewClient(rawUrl, token, map[string]string{})
-	fmt.Println(c.GetBucket("test"))
+	_, c := newTestServer(t)
+	bucket, err := c.GetBucket("test")
+	if err != nil {
+		t.Fatalf("GetBucket failed: %v", err)
+	}
+	if bucket.Id != "test" || bucket.Public {
+		t.Errorf("unexpected bucket %v", bucket)
+	}
+
+	if _, err := c.GetBucket("missing"); err == nil {
+		t.Errorf("expected an error for a missing bucket")
+	}
 }
 
 func TestBucketCreate(t *testing.T) {
-	c := storage_go.NewClient(rawUrl, token, map[string]string{})
-	fmt.Println(c.CreateBucket("test", storage_go.BucketOptions{
+	_, c := newTestServer(t)
+	if _, err := c.CreateBucket("public", storage_go.BucketOptions{
 		Public: true,
-	}))
+	}); err != nil {
+		t.Fatalf("CreateBucket failed: %v", err)
+	}
+	if _, err := c.CreateBucket("public", storage_go.BucketOptions{}); err == nil {
+		t.Errorf("expected creating a duplicate bucket to fail")
+	}
 }
 
 func TestBucketUpdate(t *testing.T) {
-	c := storage_go.NewClient(rawUrl, token, map[string]string{})
+	_, c := newTestServer(t)
 	_, _ = c.UpdateBucket("test", storage_go.BucketOptions{
 		Public: false,
 	})
@@ -38,12 +58,56 @@ func TestBucketUpdate(t *testing.T) {
 	}
 }
 
+func TestBucketConstraints(t *testing.T) {
+	_, c := newTestServer(t)
+	_, err := c.CreateBucket("images", storage_go.BucketOptions{
+		FileSizeLimit:    "8",
+		AllowedMimeTypes: []string{"image/*"},
+	})
+	if err != nil {
+		t.Fatalf("CreateBucket failed: %v", err)
+	}
+	bucket, err := c.GetBucket("images")
+	if err != nil || bucket.FileSizeLimit == nil || *bucket.FileSizeLimit != 8 || len(bucket.AllowedMimeTypes) != 1 {
+		t.Fatalf("unexpected bucket %+v (%v)", bucket, err)
+	}
+
+	png := "image/png"
+	if _, err := c.UploadFile("images", "small.png", strings.NewReader("tiny"), storage_go.FileOptions{ContentType: &png}); err != nil {
+		t.Errorf("expected a small png to be accepted: %v", err)
+	}
+	if _, err := c.UploadFile("images", "large.png", strings.NewReader("far too large"), storage_go.FileOptions{ContentType: &png}); err == nil {
+		t.Errorf("expected a file over the size limit to be rejected")
+	}
+	if _, err := c.UploadFile("images", "notes.txt", strings.NewReader("text")); err == nil {
+		t.Errorf("expected a disallowed mime type to be rejected")
+	}
+}
+
 func TestEmptyBucket(t *testing.T) {
-	c := storage_go.NewClient(rawUrl, token, map[string]string{})
-	fmt.Println(c.EmptyBucket("test"))
+	srv, c := newTestServer(t)
+	uploadDummy(t, c, "test.txt")
+
+	if _, err := c.EmptyBucket("test"); err != nil {
+		t.Fatalf("EmptyBucket failed: %v", err)
+	}
+	if _, ok := srv.Object("test", "test.txt"); ok {
+		t.Errorf("expected the bucket to be empty")
+	}
 }
 
 func TestDeleteBucket(t *testing.T) {
-	c := storage_go.NewClient(rawUrl, token, map[string]string{})
-	fmt.Println(c.DeleteBucket("test"))
+	_, c := newTestServer(t)
+	uploadDummy(t, c, "test.txt")
+
+	if _, err := c.DeleteBucket("test"); err == nil {
+		t.Errorf("expected deleting a non-empty bucket to fail")
+	}
+	_, _ = c.EmptyBucket("test")
+	if _, err := c.DeleteBucket("test"); err != nil {
+		t.Fatalf("DeleteBucket failed: %v", err)
+	}
+	if _, err := c.GetBucket("test"); err == nil {
+		t.Errorf("expected the bucket to be gone")
+	}
 }
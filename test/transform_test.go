@@ -0,0 +1,215 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	storage_go "github.com/supabase-community/storage-go"
+	"github.com/supabase-community/storage-go/storagetest"
+)
+
+// newRecordingClient returns a client for srv that records the URL and body
+// of every request.
+func newRecordingClient(t *testing.T, srv *storagetest.Server) (*storage_go.Client, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []string
+	c, err := storage_go.NewClientWithOptions(srv.URL, storage_go.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			entry := req.Method + " " + req.URL.String()
+			if req.GetBody != nil {
+				body, _ := req.GetBody()
+				data, _ := io.ReadAll(body)
+				entry += " " + string(data)
+			}
+			mu.Lock()
+			requests = append(requests, entry)
+			mu.Unlock()
+			return next.RoundTrip(req)
+		})
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), requests...)
+	}
+}
+
+func TestCreateSignedUrlTransform(t *testing.T) {
+	srv, c := newTestServer(t)
+	uploadTree(t, c, "img.png")
+	c, requests := newRecordingClient(t, srv)
+
+	resp, err := c.CreateSignedUrl("test", "img.png", 60, storage_go.UrlOptions{
+		Transform:        &storage_go.TransformOptions{Width: 200, Resize: "cover"},
+		DownloadFilename: "thumbnail.png",
+	})
+	if err != nil {
+		t.Fatalf("CreateSignedUrl failed: %v", err)
+	}
+	if !strings.HasPrefix(resp.SignedURL, srv.URL+"/render/image/sign/test/img.png?") || !strings.Contains(resp.SignedURL, "download=thumbnail.png") {
+		t.Errorf("unexpected signed URL %s", resp.SignedURL)
+	}
+
+	var body struct {
+		Transform map[string]interface{} `json:"transform"`
+	}
+	sent := requests()[0]
+	if err := json.Unmarshal([]byte(sent[strings.Index(sent, "{"):]), &body); err != nil || body.Transform["width"] != float64(200) || body.Transform["resize"] != "cover" {
+		t.Errorf("unexpected sign request %s", sent)
+	}
+
+	download, err := http.Get(resp.SignedURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	download.Body.Close()
+	if download.StatusCode != http.StatusOK || download.Header.Get("Content-Disposition") != `attachment; filename=thumbnail.png` {
+		t.Errorf("unexpected download %d %q", download.StatusCode, download.Header.Get("Content-Disposition"))
+	}
+}
+
+func TestCreateSignedUrlsTransform(t *testing.T) {
+	_, c := newTestServer(t)
+	uploadTree(t, c, "a.png", "b.png")
+
+	results, err := c.CreateSignedUrls("test", []string{"a.png", "missing.png", "b.png"}, 60, storage_go.SignedUrlsOptions{
+		UrlOptions: storage_go.UrlOptions{Transform: &storage_go.TransformOptions{Width: 100}, Download: true},
+	})
+	if err != nil {
+		t.Fatalf("CreateSignedUrls failed: %v", err)
+	}
+	if len(results) != 3 || results[1].Error == "" || results[1].SignedURL != "" {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	for _, result := range []storage_go.SignedUrlResult{results[0], results[2]} {
+		if !strings.Contains(result.SignedURL, "/render/image/sign/test/"+result.Path) || !strings.Contains(result.SignedURL, "download=true") {
+			t.Errorf("unexpected signed URL %s", result.SignedURL)
+		}
+	}
+}
+
+func TestDownloadFilename(t *testing.T) {
+	srv, c := newTestServer(t)
+	uploadTree(t, c, "report.pdf")
+
+	public := c.GetPublicUrl("test", "report.pdf", storage_go.UrlOptions{DownloadFilename: "Q1 report.pdf"})
+	if public.SignedURL != srv.URL+"/object/public/test/report.pdf?download=Q1+report.pdf" {
+		t.Errorf("unexpected public URL %s", public.SignedURL)
+	}
+
+	c, requests := newRecordingClient(t, srv)
+	body, _, err := c.DownloadFileStream("test", "report.pdf", storage_go.UrlOptions{
+		Transform:        &storage_go.TransformOptions{Width: 50},
+		DownloadFilename: "small.pdf",
+	})
+	if err != nil {
+		t.Fatalf("DownloadFileStream failed: %v", err)
+	}
+	body.Close()
+	if sent := requests()[0]; sent != "GET "+srv.URL+"/render/image/authenticated/test/report.pdf?download=small.pdf&width=50" {
+		t.Errorf("unexpected request %s", sent)
+	}
+}
+
+func TestTransformValidate(t *testing.T) {
+	valid := []storage_go.TransformOptions{
+		{},
+		{Width: 1, Height: 2500, Quality: 20},
+		{Width: 300, Resize: storage_go.ResizeCover, Format: storage_go.FormatWebp, Quality: 100},
+		{Resize: storage_go.ResizeContain, Format: storage_go.FormatOrigin},
+		{Resize: storage_go.ResizeFill, Format: storage_go.FormatAvif},
+	}
+	for _, transform := range valid {
+		if err := transform.Validate(); err != nil {
+			t.Errorf("expected %+v to be valid: %v", transform, err)
+		}
+	}
+
+	invalid := map[string]storage_go.TransformOptions{
+		"width 2501":           {Width: 2501},
+		"height -1":            {Height: -1},
+		"quality 10":           {Quality: 10},
+		`resize mode "conver"`: {Resize: "conver"},
+		`format "gif"`:         {Format: "gif"},
+	}
+	for message, transform := range invalid {
+		err := transform.Validate()
+		if !errors.Is(err, storage_go.ErrInvalidTransform) || !strings.Contains(err.Error(), message) {
+			t.Errorf("expected an error about %s, got %v", message, err)
+		}
+	}
+}
+
+func TestTransformBuilder(t *testing.T) {
+	transform, err := storage_go.NewTransform().
+		Width(400).
+		Height(300).
+		Resize(storage_go.ResizeCover).
+		Format(storage_go.FormatWebp).
+		Quality(75).
+		Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	want := storage_go.TransformOptions{Width: 400, Height: 300, Resize: "cover", Format: "webp", Quality: 75}
+	if *transform != want {
+		t.Errorf("unexpected transform %+v", transform)
+	}
+
+	if _, err := storage_go.NewTransform().Width(400).Quality(101).Build(); !errors.Is(err, storage_go.ErrInvalidTransform) {
+		t.Errorf("expected ErrInvalidTransform, got %v", err)
+	}
+}
+
+func TestTransformUrls(t *testing.T) {
+	srv, c := newTestServer(t)
+	uploadTree(t, c, "img.png")
+
+	public := c.GetPublicUrl("test", "img.png", storage_go.UrlOptions{
+		Transform: &storage_go.TransformOptions{Width: 100, Resize: storage_go.ResizeCover},
+	})
+	if public.SignedURL != srv.URL+"/render/image/public/test/img.png?resize=cover&width=100" {
+		t.Errorf("unexpected public URL %s", public.SignedURL)
+	}
+
+	invalid := storage_go.UrlOptions{Transform: &storage_go.TransformOptions{Width: 5000}}
+	if public := c.GetPublicUrl("test", "img.png", invalid); public.SignedURL != srv.URL+"/object/public/test/img.png" {
+		t.Errorf("expected the invalid transformation to be left out, got %s", public.SignedURL)
+	}
+	for _, transform := range []storage_go.TransformOptions{{Width: 100, Resize: "conver"}, {Width: 100, Quality: 500}, {Format: "gif"}} {
+		public := c.GetPublicUrl("test", "img.png", storage_go.UrlOptions{Transform: &transform, Download: true})
+		if public.SignedURL != srv.URL+"/object/public/test/img.png?download=true" {
+			t.Errorf("expected %+v to be left out, got %s", transform, public.SignedURL)
+		}
+	}
+	if public, err := c.GetPublicUrlWithTransform("test", "img.png", *invalid.Transform); public != "" || !errors.Is(err, storage_go.ErrInvalidTransform) {
+		t.Errorf("expected ErrInvalidTransform, got %q, %v", public, err)
+	}
+	transformed, err := c.GetPublicUrlWithTransform("test", "img.png", storage_go.TransformOptions{Width: 100}, storage_go.UrlOptions{Download: true})
+	if err != nil || transformed != srv.URL+"/render/image/public/test/img.png?download=true&width=100" {
+		t.Errorf("unexpected public URL %s: %v", transformed, err)
+	}
+
+	c, requests := newRecordingClient(t, srv)
+	if _, err := c.CreateSignedUrl("test", "img.png", 60, invalid); !errors.Is(err, storage_go.ErrInvalidTransform) {
+		t.Errorf("expected ErrInvalidTransform, got %v", err)
+	}
+	if _, err := c.CreateSignedUrls("test", []string{"img.png"}, 60, storage_go.SignedUrlsOptions{UrlOptions: invalid}); !errors.Is(err, storage_go.ErrInvalidTransform) {
+		t.Errorf("expected ErrInvalidTransform, got %v", err)
+	}
+	if _, err := c.DownloadFile("test", "img.png", invalid); !errors.Is(err, storage_go.ErrInvalidTransform) {
+		t.Errorf("expected ErrInvalidTransform, got %v", err)
+	}
+	if sent := requests(); len(sent) != 0 {
+		t.Errorf("expected no requests, got %v", sent)
+	}
+}
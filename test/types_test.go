@@ -0,0 +1,72 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	storage_go "github.com/supabase-community/storage-go"
+)
+
+func TestFileObjectMetadata(t *testing.T) {
+	_, c := newTestServer(t)
+	uploadTree(t, c, "docs/a.txt", "docs/sub/b.txt")
+
+	files, err := c.ListFiles("test", "docs", storage_go.FileSearchOptions{})
+	if err != nil || len(files) != 2 {
+		t.Fatalf("unexpected files %+v (%v)", files, err)
+	}
+	file, folder := files[0], files[1]
+	if file.IsFolder() || !folder.IsFolder() {
+		t.Fatalf("expected a file and a folder, got %+v", files)
+	}
+	if file.Metadata.Size != int64(len("docs/a.txt")) || file.Metadata.Mimetype == "" || file.Metadata.ETag == "" {
+		t.Errorf("unexpected metadata %+v", file.Metadata)
+	}
+	if file.UpdatedAt.IsZero() || file.CreatedAt.IsZero() || file.Metadata.LastModified.IsZero() {
+		t.Errorf("expected the dates to be parsed, got %+v", file)
+	}
+	if len(file.RawMetadata) == 0 || len(folder.RawMetadata) != 0 {
+		t.Errorf("unexpected raw metadata %s %s", file.RawMetadata, folder.RawMetadata)
+	}
+
+	bucket, err := c.GetBucket("test")
+	if err != nil || bucket.CreatedAt.IsZero() {
+		t.Errorf("expected the bucket dates to be parsed, got %+v (%v)", bucket, err)
+	}
+}
+
+func TestFileObjectDecoding(t *testing.T) {
+	want := time.Date(2024, 3, 1, 12, 30, 15, 123000000, time.UTC)
+	for _, date := range []string{
+		"2024-03-01T12:30:15.123Z",
+		"2024-03-01T12:30:15.123+00:00",
+		"2024-03-01T12:30:15.123",
+		"2024-03-01 12:30:15.123+00",
+		"2024-03-01 12:30:15.123",
+	} {
+		var file storage_go.FileObject
+		data := `{"name":"a.txt","id":"1","updated_at":"` + date + `","metadata":{"size":12,"lastModified":"` + date + `","custom":true}}`
+		if err := json.Unmarshal([]byte(data), &file); err != nil {
+			t.Fatalf("decoding %s failed: %v", date, err)
+		}
+		if !file.UpdatedAt.Equal(want) || !file.Metadata.LastModified.Equal(want) {
+			t.Errorf("unexpected dates %v %v for %s", file.UpdatedAt, file.Metadata.LastModified, date)
+		}
+		if file.Metadata.Size != 12 {
+			t.Errorf("unexpected size %d", file.Metadata.Size)
+		}
+		var raw map[string]interface{}
+		if err := json.Unmarshal(file.RawMetadata, &raw); err != nil || raw["custom"] != true {
+			t.Errorf("expected the raw metadata to be kept, got %s", file.RawMetadata)
+		}
+	}
+
+	var file storage_go.FileObject
+	if err := json.Unmarshal([]byte(`{"name":"a.txt","id":"1","updated_at":"yesterday","metadata":{"size":"large"}}`), &file); err != nil {
+		t.Fatalf("expected unknown formats to be tolerated: %v", err)
+	}
+	if !file.UpdatedAt.IsZero() || file.Metadata.Size != 0 || file.Name != "a.txt" {
+		t.Errorf("unexpected file %+v", file)
+	}
+}
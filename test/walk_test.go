@@ -0,0 +1,165 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	storage_go "github.com/supabase-community/storage-go"
+)
+
+func uploadTree(t *testing.T, c *storage_go.Client, keys ...string) {
+	t.Helper()
+	for _, key := range keys {
+		if _, err := c.UploadFile("test", key, strings.NewReader(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	_, c := newTestServer(t)
+	uploadTree(t, c, "a.txt", "docs/b.txt", "docs/sub/c.txt", "docs/sub/d.txt", "img/deep/f.png", "img/e.png")
+
+	var keys []string
+	err := c.Walk("test", "", func(key string, file storage_go.FileObject, err error) error {
+		if err != nil {
+			return err
+		}
+		if file.Id == "" {
+			key += "/"
+		}
+		keys = append(keys, key)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	want := []string{"a.txt", "docs/", "img/", "docs/b.txt", "docs/sub/", "docs/sub/c.txt", "docs/sub/d.txt", "img/deep/", "img/e.png", "img/deep/f.png"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("unexpected keys\n got %v\nwant %v", keys, want)
+	}
+
+	keys = nil
+	_ = c.Walk("test", "/docs/", func(key string, file storage_go.FileObject, err error) error {
+		keys = append(keys, key)
+		return err
+	})
+	if want := []string{"docs/b.txt", "docs/sub", "docs/sub/c.txt", "docs/sub/d.txt"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("unexpected keys under docs\n got %v\nwant %v", keys, want)
+	}
+}
+
+func TestWalkSkipDir(t *testing.T) {
+	_, c := newTestServer(t)
+	uploadTree(t, c, "docs/b.txt", "docs/sub/c.txt", "img/deep/f.png", "img/e.png", "img/z.png")
+
+	var keys []string
+	err := c.Walk("test", "", func(key string, file storage_go.FileObject, err error) error {
+		keys = append(keys, key)
+		if key == "docs" || key == "img/e.png" {
+			return storage_go.SkipDir
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	if want := []string{"docs", "img", "img/deep", "img/e.png", "img/deep/f.png"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("unexpected keys\n got %v\nwant %v", keys, want)
+	}
+}
+
+func TestWalkSkipDirNotListed(t *testing.T) {
+	srv, c := newTestServer(t)
+	uploadTree(t, c, "docs/b.txt", "docs/sub/c.txt", "img/e.png")
+	c, requests := newRecordingClient(t, srv)
+
+	err := c.Walk("test", "", func(key string, file storage_go.FileObject, err error) error {
+		if key == "docs" {
+			return storage_go.SkipDir
+		}
+		return err
+	}, storage_go.WalkOptions{Concurrency: 4})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	var prefixes []string
+	for _, sent := range requests() {
+		var body struct {
+			Prefix string `json:"prefix"`
+		}
+		if err := json.Unmarshal([]byte(sent[strings.Index(sent, "{"):]), &body); err != nil {
+			t.Fatal(err)
+		}
+		prefixes = append(prefixes, body.Prefix)
+	}
+	if want := []string{"", "img"}; !reflect.DeepEqual(prefixes, want) {
+		t.Errorf("expected only the walked folders to be listed, got %v", prefixes)
+	}
+}
+
+func TestWalkStop(t *testing.T) {
+	_, c := newTestServer(t)
+	uploadTree(t, c, "a/1.txt", "b/2.txt", "c/3.txt")
+
+	errStop := errors.New("stop")
+	calls := 0
+	err := c.Walk("test", "", func(key string, file storage_go.FileObject, err error) error {
+		calls++
+		if key == "b/2.txt" {
+			return errStop
+		}
+		return err
+	}, storage_go.WalkOptions{Concurrency: 4})
+	if err != errStop {
+		t.Errorf("expected the error of the WalkFunc, got %v", err)
+	}
+	if calls != 5 {
+		t.Errorf("expected 5 calls, got %d", calls)
+	}
+}
+
+func TestWalkListError(t *testing.T) {
+	_, c := newTestServer(t)
+
+	var gotKey string
+	err := c.Walk("missing", "folder", func(key string, file storage_go.FileObject, err error) error {
+		gotKey = key
+		return err
+	})
+	if !errors.Is(err, storage_go.ErrNotFound) || gotKey != "folder" {
+		t.Errorf("expected ErrNotFound for folder, got %q %v", gotKey, err)
+	}
+}
+
+func TestWalkConcurrency(t *testing.T) {
+	_, c := newTestServer(t)
+	var keys []string
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 3; j++ {
+			keys = append(keys, fmt.Sprintf("f%d/g%d/file.txt", i, j))
+		}
+	}
+	uploadTree(t, c, keys...)
+
+	var walked []string
+	err := c.Walk("test", "", func(key string, file storage_go.FileObject, err error) error {
+		if err != nil {
+			return err
+		}
+		if file.Id != "" {
+			walked = append(walked, key)
+		}
+		return nil
+	}, storage_go.WalkOptions{Concurrency: 4})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	if !reflect.DeepEqual(walked, keys) {
+		t.Errorf("unexpected keys\n got %v\nwant %v", walked, keys)
+	}
+}
@@ -0,0 +1,128 @@
+package storage_go
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidTransform is wrapped by the errors of TransformOptions.Validate.
+var ErrInvalidTransform = errors.New("storage: invalid image transformation")
+
+// ResizeMode is how an image is resized to the width and height of a
+// transformation.
+type ResizeMode string
+
+const (
+	// ResizeCover fills the width and height, cropping the image if needed.
+	// It is the default of the server.
+	ResizeCover ResizeMode = "cover"
+	// ResizeContain fits the image within the width and height, keeping its
+	// aspect ratio.
+	ResizeContain ResizeMode = "contain"
+	// ResizeFill stretches the image to the width and height.
+	ResizeFill ResizeMode = "fill"
+)
+
+// ImageFormat is the format of a transformed image.
+type ImageFormat string
+
+const (
+	// FormatOrigin keeps the format of the original image. By default the
+	// server picks the best format supported by the browser.
+	FormatOrigin ImageFormat = "origin"
+	FormatAvif   ImageFormat = "avif"
+	FormatWebp   ImageFormat = "webp"
+)
+
+// Limits of the image transformations accepted by the server.
+const (
+	MinTransformSize    = 1
+	MaxTransformSize    = 2500
+	MinTransformQuality = 20
+	MaxTransformQuality = 100
+)
+
+// Validate checks the values of t against the limits of the server. Zero
+// values are left to the server defaults.
+func (t TransformOptions) Validate() error {
+	if t.Width != 0 && (t.Width < MinTransformSize || t.Width > MaxTransformSize) {
+		return fmt.Errorf("%w: width %d is not between %d and %d", ErrInvalidTransform, t.Width, MinTransformSize, MaxTransformSize)
+	}
+	if t.Height != 0 && (t.Height < MinTransformSize || t.Height > MaxTransformSize) {
+		return fmt.Errorf("%w: height %d is not between %d and %d", ErrInvalidTransform, t.Height, MinTransformSize, MaxTransformSize)
+	}
+	if t.Quality != 0 && (t.Quality < MinTransformQuality || t.Quality > MaxTransformQuality) {
+		return fmt.Errorf("%w: quality %d is not between %d and %d", ErrInvalidTransform, t.Quality, MinTransformQuality, MaxTransformQuality)
+	}
+	switch t.Resize {
+	case "", ResizeCover, ResizeContain, ResizeFill:
+	default:
+		return fmt.Errorf("%w: resize mode %q is not %q, %q or %q", ErrInvalidTransform, t.Resize, ResizeCover, ResizeContain, ResizeFill)
+	}
+	switch t.Format {
+	case "", FormatOrigin, FormatAvif, FormatWebp:
+	default:
+		return fmt.Errorf("%w: format %q is not %q, %q or %q", ErrInvalidTransform, t.Format, FormatOrigin, FormatAvif, FormatWebp)
+	}
+	return nil
+}
+
+// validate checks the transformation of o, if any.
+func (o UrlOptions) validate() error {
+	if o.Transform == nil {
+		return nil
+	}
+	return o.Transform.Validate()
+}
+
+// TransformBuilder builds TransformOptions with chained calls:
+//
+//	transform, err := storage_go.NewTransform().Width(400).Height(300).Resize(storage_go.ResizeContain).Build()
+type TransformBuilder struct {
+	options TransformOptions
+}
+
+// NewTransform starts building a transformation.
+func NewTransform() *TransformBuilder {
+	return &TransformBuilder{}
+}
+
+// Width sets the width of the image, in pixels.
+func (b *TransformBuilder) Width(width int) *TransformBuilder {
+	b.options.Width = width
+	return b
+}
+
+// Height sets the height of the image, in pixels.
+func (b *TransformBuilder) Height(height int) *TransformBuilder {
+	b.options.Height = height
+	return b
+}
+
+// Resize sets how the image is resized to the width and height.
+func (b *TransformBuilder) Resize(mode ResizeMode) *TransformBuilder {
+	b.options.Resize = mode
+	return b
+}
+
+// Format sets the format of the image.
+func (b *TransformBuilder) Format(format ImageFormat) *TransformBuilder {
+	b.options.Format = format
+	return b
+}
+
+// Quality sets the quality of the image, from 20 to 100.
+func (b *TransformBuilder) Quality(quality int) *TransformBuilder {
+	b.options.Quality = quality
+	return b
+}
+
+// Build validates the transformation and returns it, ready to be set as
+// UrlOptions.Transform.
+func (b *TransformBuilder) Build() (*TransformOptions, error) {
+	if err := b.options.Validate(); err != nil {
+		return nil, err
+	}
+	options := b.options
+	return &options, nil
+}
@@ -1,5 +1,10 @@
 package storage_go
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type MessageResponse struct {
 	Message string `json:"message"`
 }
@@ -11,14 +16,14 @@ type BucketResponseError struct {
 }
 
 type Bucket struct {
-	Id               string   `json:"id"`
-	Name             string   `json:"name"`
-	Owner            string   `json:"owner"`
-	Public           bool     `json:"public"`
-	FileSizeLimit    *int64   `json:"file_size_limit"`
-	AllowedMimeTypes []string `json:"allowed_mine_types"`
-	CreatedAt        string   `json:"created_at"`
-	UpdatedAt        string   `json:"updated_at"`
+	Id               string    `json:"id"`
+	Name             string    `json:"name"`
+	Owner            string    `json:"owner"`
+	Public           bool      `json:"public"`
+	FileSizeLimit    *int64    `json:"file_size_limit"`
+	AllowedMimeTypes []string  `json:"allowed_mime_types"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
 }
 
 // BucketOptions is used to create or update a Bucket with option
@@ -34,6 +39,7 @@ type SortBy struct {
 }
 
 type FileUploadResponse struct {
+	Id      string `json:"Id"`
 	Key     string `json:"Key"`
 	Message string `json:"message"`
 	Data    []byte
@@ -45,6 +51,43 @@ type SignedUrlResponse struct {
 	SignedURL string `json:"signedURL"`
 }
 
+// SignedUrlsOptions configures CreateSignedUrls.
+type SignedUrlsOptions struct {
+	// BatchSize is the number of paths signed per request. Defaults to 500.
+	BatchSize int
+	// UrlOptions sets the transformation and download options of every URL.
+	// The server signs transformed images one at a time, so a Transform costs
+	// one request per path.
+	UrlOptions
+}
+
+// SignedUrlResult is the signed URL of a path of CreateSignedUrls.
+type SignedUrlResult struct {
+	Path      string `json:"path"`
+	SignedURL string `json:"signedURL"`
+	// Error is set when the path could not be signed, e.g. because the file
+	// does not exist.
+	Error string `json:"error"`
+}
+
+// CopyOptions configures CopyFile and MoveFile.
+type CopyOptions struct {
+	// DestinationBucket is the bucket of the destination. Defaults to the
+	// bucket of the source.
+	DestinationBucket string
+	// Upsert overwrites the destination if it exists. Otherwise an error is
+	// returned.
+	Upsert bool
+	// CacheControl, e.g. `max-age=3600`, and ContentType replace the metadata
+	// of the source for a copy. By default the metadata of the source is copied.
+	CacheControl *string
+	ContentType  *string
+	// Metadata and MetadataJSON replace the user metadata of a copy, as in
+	// FileOptions.
+	Metadata     map[string]string
+	MetadataJSON json.RawMessage
+}
+
 type FileSearchOptions struct {
 	Limit         int    `json:"limit"`
 	Offset        int    `json:"offset"`
@@ -52,15 +95,37 @@ type FileSearchOptions struct {
 }
 
 type FileObject struct {
-	Name           string      `json:"name"`
-	BucketId       string      `json:"bucket_id"`
-	Owner          string      `json:"owner"`
-	Id             string      `json:"id"`
-	UpdatedAt      string      `json:"updated_at"`
-	CreatedAt      string      `json:"created_at"`
-	LastAccessedAt string      `json:"last_accessed_at"`
-	Metadata       interface{} `json:"metadata"`
-	Buckets        Bucket
+	Name           string         `json:"name"`
+	BucketId       string         `json:"bucket_id"`
+	Owner          string         `json:"owner"`
+	Id             string         `json:"id"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+	LastAccessedAt time.Time      `json:"last_accessed_at"`
+	Metadata       ObjectMetadata `json:"metadata"`
+	// UserMetadata is the custom metadata set with FileOptions.Metadata.
+	UserMetadata map[string]interface{} `json:"user_metadata"`
+	// RawMetadata is the metadata as sent by the server, including fields that
+	// are not in ObjectMetadata. It is empty for folders.
+	RawMetadata json.RawMessage `json:"-"`
+	Buckets     Bucket
+}
+
+// IsFolder reports whether f is a folder of a listing. Folders have no id
+// and no metadata.
+func (f FileObject) IsFolder() bool {
+	return f.Id == ""
+}
+
+// ObjectMetadata is the metadata of a file in a listing.
+type ObjectMetadata struct {
+	ETag           string    `json:"eTag"`
+	Size           int64     `json:"size"`
+	Mimetype       string    `json:"mimetype"`
+	CacheControl   string    `json:"cacheControl"`
+	LastModified   time.Time `json:"lastModified"`
+	ContentLength  int64     `json:"contentLength"`
+	HttpStatusCode int       `json:"httpStatusCode"`
 }
 
 type ListFileRequestBody struct {
@@ -70,17 +135,71 @@ type ListFileRequestBody struct {
 	Prefix        string `json:"prefix"`
 }
 
+// ListObjectsV2Request is the body of a list-v2 request. Limit defaults to
+// 1000 on the server.
+type ListObjectsV2Request struct {
+	Prefix string `json:"prefix,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+	// Cursor is the NextCursor of the previous page.
+	Cursor string `json:"cursor,omitempty"`
+	// WithDelimiter returns the folders directly under Prefix in Folders
+	// instead of listing their objects recursively.
+	WithDelimiter bool    `json:"with_delimiter"`
+	SortBy        *SortBy `json:"sortBy,omitempty"`
+}
+
+// ListObjectsV2Response is a page of a list-v2 request. Names are full keys
+// relative to the bucket.
+type ListObjectsV2Response struct {
+	HasNext    bool           `json:"hasNext"`
+	NextCursor string         `json:"nextCursor"`
+	Folders    []FolderObject `json:"folders"`
+	Objects    []FileObject   `json:"objects"`
+}
+
+// FolderObject is a folder of a list-v2 response, e.g. `folder/subfolder/`.
+type FolderObject struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// TransformOptions transforms an image when it is served. Build it directly or
+// with NewTransform, and check it with Validate.
 type TransformOptions struct {
-	Width   int    `json:"width"`
-	Height  int    `json:"height"`
-	Resize  string `json:"resize"`
-	Format  string `json:"format"`
-	Quality int    `json:"quality"`
+	Width   int         `json:"width,omitempty"`
+	Height  int         `json:"height,omitempty"`
+	Resize  ResizeMode  `json:"resize,omitempty"`
+	Format  ImageFormat `json:"format,omitempty"`
+	Quality int         `json:"quality,omitempty"`
 }
 
 type UrlOptions struct {
 	Transform *TransformOptions `json:"transform"`
-	Download  bool              `json:"download"`
+	// Download makes the browser download the file instead of displaying it.
+	Download bool `json:"download"`
+	// DownloadFilename makes the browser download the file under this name.
+	// It implies Download.
+	DownloadFilename string `json:"-"`
+}
+
+// ObjectInfo is the metadata of an object as reported by the headers of a
+// download, or by GetFileInfo.
+type ObjectInfo struct {
+	ContentType string
+	// ContentLength is -1 when the server did not report the size.
+	ContentLength int64
+	ETag          string
+	LastModified  time.Time
+	CacheControl  string
+
+	// The fields below are only set by GetFileInfo.
+	Id        string
+	Name      string
+	BucketId  string
+	Version   string
+	CreatedAt time.Time
+	// Metadata is the user metadata of the object.
+	Metadata map[string]interface{}
 }
 
 type SignedUploadUrlResponse struct {
@@ -102,4 +221,91 @@ type FileOptions struct {
 	// When upsert is set to true, the file is overwritten if it exists. When set to false, an error is thrown if the object already exists.
 	// Defaults to false.
 	Upsert *bool
+	// Metadata is custom user metadata stored with the object, such as the
+	// tenant or the source of an upload. It is sent in the `x-metadata` header.
+	Metadata map[string]string
+	// MetadataJSON is user metadata of any JSON shape. It takes precedence
+	// over Metadata.
+	MetadataJSON json.RawMessage
+}
+
+// UnmarshalJSON decodes a bucket, accepting the date formats of parseTime.
+func (b *Bucket) UnmarshalJSON(data []byte) error {
+	type bucket Bucket
+	aux := struct {
+		*bucket
+		CreatedAt *string `json:"created_at"`
+		UpdatedAt *string `json:"updated_at"`
+	}{bucket: (*bucket)(b)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	b.CreatedAt = parseTime(aux.CreatedAt)
+	b.UpdatedAt = parseTime(aux.UpdatedAt)
+	return nil
+}
+
+// UnmarshalJSON decodes a file, accepting the date formats of parseTime. The
+// metadata is decoded on a best-effort basis and kept as is in RawMetadata.
+func (f *FileObject) UnmarshalJSON(data []byte) error {
+	type fileObject FileObject
+	aux := struct {
+		*fileObject
+		UpdatedAt      *string         `json:"updated_at"`
+		CreatedAt      *string         `json:"created_at"`
+		LastAccessedAt *string         `json:"last_accessed_at"`
+		Metadata       json.RawMessage `json:"metadata"`
+	}{fileObject: (*fileObject)(f)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	f.UpdatedAt = parseTime(aux.UpdatedAt)
+	f.CreatedAt = parseTime(aux.CreatedAt)
+	f.LastAccessedAt = parseTime(aux.LastAccessedAt)
+	f.Metadata = ObjectMetadata{}
+	f.RawMetadata = nil
+	if len(aux.Metadata) > 0 && string(aux.Metadata) != "null" {
+		f.RawMetadata = append(json.RawMessage(nil), aux.Metadata...)
+		_ = json.Unmarshal(aux.Metadata, &f.Metadata)
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes metadata, accepting the date formats of parseTime.
+func (m *ObjectMetadata) UnmarshalJSON(data []byte) error {
+	type objectMetadata ObjectMetadata
+	aux := struct {
+		*objectMetadata
+		LastModified *string `json:"lastModified"`
+	}{objectMetadata: (*objectMetadata)(m)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	m.LastModified = parseTime(aux.LastModified)
+	return nil
+}
+
+// timeLayouts are the date formats sent by the different versions of the
+// Storage API and its database.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999",
+	time.RFC1123,
+}
+
+// parseTime parses a date in any of timeLayouts. Dates without a time zone
+// are in UTC. A missing or unknown date is the zero time.
+func parseTime(value *string) time.Time {
+	if value == nil {
+		return time.Time{}
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, *value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
 }
@@ -0,0 +1,184 @@
+package storage_go
+
+import (
+	"context"
+	"io/fs"
+	"strings"
+	"sync"
+)
+
+// SkipDir is used as a return value from a WalkFunc to skip the folder named
+// in the call. It is the same value as fs.SkipDir.
+var SkipDir = fs.SkipDir
+
+// WalkFunc is the type of the function called by Walk for each file and
+// folder. key is the full key of the entry in the bucket, e.g.
+// `folder/subfolder/filename.png`, and file is the entry as returned by
+// ListFiles; folders are reported by IsFolder.
+//
+// If listing a folder fails, the function is called a second time for that
+// folder with the error; the folder of the prefix passed to Walk is reported
+// with an empty FileObject. Returning nil skips the folder and continues the
+// walk. Returning SkipDir for a folder skips it, and for a file skips the
+// remaining entries of its folder; the subfolders accepted before the file are
+// still walked. Any other error stops the walk and is returned by Walk.
+type WalkFunc func(key string, file FileObject, err error) error
+
+// WalkOptions configures Walk.
+type WalkOptions struct {
+	// Concurrency is the number of folders listed in parallel. Defaults to 1.
+	// The WalkFunc is never called concurrently, whatever its value.
+	Concurrency int
+}
+
+// Walk walks the folders of a bucket below prefix. fn is called for the
+// entries of a folder in the order they are listed, then Walk descends into
+// the subfolders fn accepted, one after the other. A folder is only listed
+// once fn has accepted it, so a skipped folder costs no request. The folders
+// of the prefix itself are not passed to fn.
+// bucketId string The bucket id
+// prefix string The folder to walk, or an empty string for the whole bucket
+// fn WalkFunc The function called for each entry
+// options WalkOptions The concurrency options
+func (c *Client) Walk(bucketId string, prefix string, fn WalkFunc, options ...WalkOptions) error {
+	return c.WalkWithContext(context.Background(), bucketId, prefix, fn, options...)
+}
+
+// WalkWithContext is like Walk but carries ctx on the outgoing requests.
+func (c *Client) WalkWithContext(ctx context.Context, bucketId string, prefix string, fn WalkFunc, options ...WalkOptions) error {
+	concurrency := 1
+	if len(options) > 0 && options[0].Concurrency > 1 {
+		concurrency = options[0].Concurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	w := &walker{
+		client:   c,
+		ctx:      ctx,
+		bucketId: bucketId,
+		fn:       fn,
+		jobs:     make(chan *pendingFolder, concurrency),
+	}
+	for i := 0; i < concurrency; i++ {
+		w.wg.Add(1)
+		go w.work()
+	}
+	defer w.wg.Wait()
+	defer close(w.jobs)
+	defer cancel()
+
+	err := w.walk(strings.Trim(prefix, "/"), concurrency)
+	if err == SkipDir {
+		return nil
+	}
+	return err
+}
+
+type walker struct {
+	client   *Client
+	ctx      context.Context
+	bucketId string
+	fn       WalkFunc
+	jobs     chan *pendingFolder
+	wg       sync.WaitGroup
+}
+
+// pendingFolder is a folder accepted by the WalkFunc that is yet to be walked.
+// listing is nil until the folder is handed to a worker.
+type pendingFolder struct {
+	key     string
+	folder  FileObject
+	listing chan folderListing
+}
+
+type folderListing struct {
+	files []FileObject
+	err   error
+}
+
+// work lists the folders of the jobs queue until it is closed.
+func (w *walker) work() {
+	defer w.wg.Done()
+	for folder := range w.jobs {
+		if err := w.ctx.Err(); err != nil {
+			folder.listing <- folderListing{err: err}
+			continue
+		}
+		var files []FileObject
+		it := w.client.ListFilesIterWithContext(w.ctx, w.bucketId, folder.key)
+		for it.Next() {
+			files = append(files, it.Object())
+		}
+		folder.listing <- folderListing{files: files, err: it.Err()}
+	}
+}
+
+// walk walks the folder prefix and its subfolders in order. The folders next
+// in line are handed to the workers ahead of time, but no more than
+// concurrency listings are pending at once so that they are never buffered
+// for long.
+func (w *walker) walk(prefix string, concurrency int) error {
+	// stack holds the folders to walk, the next one last.
+	stack := []*pendingFolder{{key: prefix}}
+	pending := 0
+	for len(stack) > 0 {
+		for i := len(stack) - 1; i >= 0 && pending < concurrency; i-- {
+			if stack[i].listing == nil {
+				stack[i].listing = make(chan folderListing, 1)
+				w.jobs <- stack[i]
+				pending++
+			}
+		}
+
+		folder := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		result := <-folder.listing
+		pending--
+
+		subfolders, err := w.walkFolder(folder, result)
+		if err != nil {
+			return err
+		}
+		for i := len(subfolders) - 1; i >= 0; i-- {
+			stack = append(stack, subfolders[i])
+		}
+	}
+	return nil
+}
+
+// walkFolder calls fn for the entries of a listed folder and returns the
+// subfolders it accepted.
+func (w *walker) walkFolder(folder *pendingFolder, result folderListing) ([]*pendingFolder, error) {
+	if result.err != nil {
+		if err := w.fn(folder.key, folder.folder, result.err); err != nil && err != SkipDir {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	var subfolders []*pendingFolder
+	for _, file := range result.files {
+		fileKey := joinKey(folder.key, file.Name)
+		if err := w.fn(fileKey, file, nil); err != nil {
+			if err != SkipDir {
+				return nil, err
+			}
+			if file.IsFolder() {
+				continue
+			}
+			break
+		}
+		if file.IsFolder() {
+			subfolders = append(subfolders, &pendingFolder{key: fileKey, folder: file})
+		}
+	}
+	return subfolders, nil
+}
+
+// joinKey joins a folder key and an entry name.
+func joinKey(folder string, name string) string {
+	if folder == "" {
+		return name
+	}
+	return folder + "/" + name
+}